@@ -0,0 +1,457 @@
+// Program discovery - wyszukuje procesy do monitorowania i generuje konfigurację monitor_mutex
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"monitor_mutex/config"
+	"monitor_mutex/discovery"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Opcje uruchomienia discovery (z flag wiersza poleceń)
+type discoveryOptions struct {
+	selectors discovery.SelectorList // Kryteria --select; puste = wybór interaktywny
+	minAge    time.Duration          // Pomiń procesy młodsze niż --min-age
+	output    string                 // Plik wynikowy (--output)
+	yes       bool                   // Odpowiedz "tak" na wszystkie pytania (--yes)
+	dryRun    bool                   // Tylko wypisz konfigurację (--dry-run)
+	format    string                 // Raport kandydatów zamiast konfiguracji (--format json|table)
+	merge     bool                   // Dopisz nowe procesy do istniejącego pliku (--merge)
+	policy    discovery.Policy       // Zasady oceny kandydatów (--include-managed, --rules)
+	observe   time.Duration          // Czas obserwacji logów przed wyliczeniem timeoutów (--observe)
+	imports   importList             // Pliki supervisord, systemd lub Procfile (--import) zamiast wykrywania
+	importFmt string                 // Format importowanych plików (--import-format, puste = rozpoznaj)
+}
+
+// Lista plików z powtarzanej flagi --import
+type importList []string
+
+func (l *importList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *importList) Set(filename string) error {
+	*l = append(*l, filename)
+	return nil
+}
+
+// Czy discovery działa bez pytań na stdin
+func (o *discoveryOptions) unattended() bool {
+	return o.yes || len(o.selectors) > 0
+}
+
+// Wykryj kandydatów, odrzuć zbyt młode procesy (--min-age) i ułóż od najlepiej ocenionych
+func findCandidates(opts *discoveryOptions) []discovery.ProcessCandidate {
+	candidates := discovery.Discover()
+
+	if opts.minAge > 0 {
+		var old []discovery.ProcessCandidate
+		for _, candidate := range candidates {
+			if candidate.Age >= opts.minAge {
+				old = append(old, candidate)
+			}
+		}
+		fmt.Printf("   Pominięto %d procesów młodszych niż %s\n\n", len(candidates)-len(old), opts.minAge)
+		candidates = old
+	}
+
+	discovery.RankCandidates(candidates, opts.policy)
+	return candidates
+}
+
+// Wybór procesów - interaktywny albo na podstawie --select / --yes
+func selectProcessesToMonitor(opts *discoveryOptions, reader *bufio.Reader) []discovery.ProcessCandidate {
+	candidates := findCandidates(opts)
+
+	if len(candidates) == 0 {
+		fmt.Println("❌ Nie znaleziono kandydatów do monitorowania")
+		fmt.Println("Spróbuj ręcznej konfiguracji lub uruchom więcej procesów")
+		return nil
+	}
+
+	printCandidates(candidates)
+
+	if len(opts.selectors) > 0 {
+		selected := opts.selectors.Filter(candidates)
+		fmt.Printf("Wybrano %d z %d kandydatów na podstawie --select\n", len(selected), len(candidates))
+		return selected
+	}
+	if opts.yes {
+		fmt.Printf("Wybrano wszystkich %d kandydatów (--yes)\n", len(candidates))
+		return candidates
+	}
+
+	// Czytaj wybór użytkownika
+	fmt.Print("Wybierz numery procesów do monitorowania (np: 1,3,5, 2.1 = pierwszy potomny z 2 lub 'all' dla wszystkich): ")
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+
+	if input == "" {
+		return nil
+	}
+
+	var selected []discovery.ProcessCandidate
+
+	if input == "all" {
+		selected = candidates
+	} else {
+		for _, numStr := range strings.Split(input, ",") {
+			numStr = strings.TrimSpace(numStr)
+			if candidate, ok := pickCandidate(candidates, numStr); ok {
+				selected = append(selected, candidate)
+			}
+		}
+	}
+
+	return selected
+}
+
+// Kandydat o numerze z listy; "N.M" wybiera M-ty proces potomny kandydata N jako korzeń
+func pickCandidate(candidates []discovery.ProcessCandidate, numStr string) (discovery.ProcessCandidate, bool) {
+	rootStr, childStr, isChild := strings.Cut(numStr, ".")
+	num, err := strconv.Atoi(rootStr)
+	if err != nil || num < 1 || num > len(candidates) {
+		return discovery.ProcessCandidate{}, false
+	}
+	if !isChild {
+		return candidates[num-1], true
+	}
+	child, err := strconv.Atoi(childStr)
+	if err != nil {
+		return discovery.ProcessCandidate{}, false
+	}
+	return candidates[num-1].ChildRoot(child)
+}
+
+// Wypisz listę kandydatów
+func printCandidates(candidates []discovery.ProcessCandidate) {
+	fmt.Println("📋 Znalezione kandydaci do monitorowania:")
+	fmt.Println("==========================================")
+
+	for i, candidate := range candidates {
+		fmt.Printf("[%2d] %-20s", i+1, candidate.Name)
+
+		if candidate.PID != "" {
+			fmt.Printf(" PID: %-8s", candidate.PID)
+		}
+
+		if candidate.Port != "" {
+			fmt.Printf(" Port: %-6s", candidate.Port)
+		}
+
+		if candidate.CPUUsage != "" {
+			fmt.Printf(" CPU: %-5s%%", candidate.CPUUsage)
+		}
+
+		fmt.Printf(" Ocena: %d\n", candidate.Score.Total)
+		if len(candidate.Score.Items) > 0 {
+			fmt.Printf("     Punkty: %s\n", candidate.Score)
+		}
+
+		if candidate.ConfigName != "" && candidate.ConfigName != candidate.Name {
+			fmt.Printf("     Nazwa: %s\n", candidate.ConfigName)
+		}
+
+		for j, target := range candidate.LogTargets {
+			label := "Log:"
+			if j > 0 {
+				label = "    " // Kolejne, mniej prawdopodobne miejsca
+			}
+			fmt.Printf("     %s %s\n", label, target)
+		}
+
+		if candidate.ManagedBy.Managed() {
+			fmt.Printf("     Zarządca: %s\n", candidate.ManagedBy)
+		}
+
+		if candidate.Command != "" {
+			cmd := candidate.Command
+			if len(cmd) > 60 {
+				cmd = cmd[:57] + "..."
+			}
+			fmt.Printf("     Cmd: %s\n", cmd)
+		}
+
+		if len(candidate.Children) > 0 {
+			children := make([]string, len(candidate.Children))
+			for j, child := range candidate.Children {
+				children[j] = fmt.Sprintf("[%d.%d] %s", i+1, j+1, child)
+			}
+			fmt.Printf("     Potomne: %s\n", strings.Join(children, ", "))
+		}
+
+		fmt.Println()
+	}
+}
+
+// Domyślny plik wynikowy
+const defaultOutputFile = "monitor_config.yaml"
+
+// Ustal nazwę pliku wynikowego i upewnij się, że można go nadpisać
+func chooseOutputFile(opts *discoveryOptions, reader *bufio.Reader) (string, bool) {
+	filename := opts.output
+
+	if filename == "" && !opts.yes {
+		fmt.Print("\nCzy zapisać konfigurację do pliku? (t/n): ")
+		input, _ := reader.ReadString('\n')
+		if strings.TrimSpace(strings.ToLower(input)) != "t" {
+			return "", false
+		}
+
+		fmt.Printf("Podaj nazwę pliku (enter = %s): ", defaultOutputFile)
+		filename, _ = reader.ReadString('\n')
+		filename = strings.TrimSpace(filename)
+
+		if filename != "" && !strings.HasSuffix(filename, ".yaml") && !strings.HasSuffix(filename, ".yml") {
+			filename += ".yaml"
+		}
+	}
+	if filename == "" {
+		filename = defaultOutputFile
+	}
+
+	if _, err := os.Stat(filename); err == nil && !opts.yes && !opts.merge {
+		if opts.unattended() {
+			fmt.Printf("❌ Plik %s już istnieje - użyj --yes, aby go nadpisać\n", filename)
+			return "", false
+		}
+		fmt.Printf("Plik %s już istnieje. Nadpisać? (t/n): ", filename)
+		input, _ := reader.ReadString('\n')
+		if strings.TrimSpace(strings.ToLower(input)) != "t" {
+			return "", false
+		}
+	}
+
+	return filename, true
+}
+
+// Scal konfigurację z istniejącym plikiem: pokaż różnice, zapytaj i zapisz z kopią zapasową
+func mergeIntoFile(opts *discoveryOptions, reader *bufio.Reader, configs []config.ProcessConfig, filename string) (bool, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return false, fmt.Errorf("nie można odczytać %s: %v", filename, err)
+	}
+
+	merge, err := discovery.MergeConfiguration(data, configs)
+	if err != nil {
+		return false, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	fmt.Printf("\n🔀 Scalanie z %s:\n", filename)
+	merge.PrintDiff(os.Stdout, filename)
+
+	if len(merge.Added) == 0 {
+		fmt.Println("\n✅ Brak nowych procesów - plik pozostaje bez zmian")
+		return false, nil
+	}
+	if opts.dryRun {
+		fmt.Println("\n📄 --dry-run: nic nie zapisano")
+		return false, nil
+	}
+
+	if !opts.unattended() {
+		fmt.Print("\nZapisać zmiany? (t/n): ")
+		input, _ := reader.ReadString('\n')
+		if strings.TrimSpace(strings.ToLower(input)) != "t" {
+			return false, nil
+		}
+	}
+
+	backup, err := discovery.WriteMergedConfiguration(filename, merge)
+	if err != nil {
+		return false, fmt.Errorf("błąd zapisu: %v", err)
+	}
+	fmt.Printf("✅ Dodano %d procesów do %s (kopia zapasowa: %s)\n", len(merge.Added), filename, backup)
+	return true, nil
+}
+
+// Importuje definicje z plików --import i wypisuje, czego nie dało się przenieść
+func importProcesses(opts *discoveryOptions) ([]config.ProcessConfig, error) {
+	var imported []discovery.ImportedProcess
+	for _, filename := range opts.imports {
+		entries, err := discovery.ImportFile(filename, opts.importFmt, opts.policy.Rules)
+		if err != nil {
+			return nil, err
+		}
+		imported = append(imported, entries...)
+	}
+
+	configs := discovery.ImportedConfigs(imported)
+	fmt.Printf("📥 Zaimportowano %d procesów:\n", len(configs))
+	fmt.Println("==============================")
+	unmapped := 0
+	for i, p := range imported {
+		fmt.Printf("✅ %s (%s) - profil %s: timeout %ds, interval %ds, log %s\n",
+			configs[i].Name, p.Source, p.Profile, configs[i].Timeout, configs[i].Interval, configs[i].LogFile)
+		for _, note := range p.Unmapped {
+			fmt.Printf("   ⚠️  %s\n", note)
+		}
+		unmapped += len(p.Unmapped)
+	}
+	if unmapped > 0 {
+		fmt.Printf("\n%d opcji bez odpowiednika w monitor_mutex - sprawdź je przed uruchomieniem\n", unmapped)
+	}
+	return configs, nil
+}
+
+// Główna funkcja discovery
+func main() {
+	var opts discoveryOptions
+	flag.Var(&opts.selectors, "select", "wybierz kandydatów bez pytania: all, name=regex, user=nazwa, port=numer (można powtarzać)")
+	flag.DurationVar(&opts.minAge, "min-age", 0, "pomiń procesy działające krócej niż podany czas (np. 10m, 2h)")
+	flag.StringVar(&opts.output, "output", "", "zapisz konfigurację do pliku bez pytania")
+	flag.BoolVar(&opts.yes, "yes", false, "odpowiedz \"tak\" na wszystkie pytania (bez --select wybiera wszystkich kandydatów)")
+	flag.BoolVar(&opts.dryRun, "dry-run", false, "wypisz konfigurację na stdout zamiast zapisywać plik")
+	flag.BoolVar(&opts.merge, "merge", false, "dopisz nowe procesy do istniejącego pliku zamiast go nadpisywać (kopia w .bak)")
+	flag.BoolVar(&opts.policy.IncludeManaged, "include-managed", false, "nie pomijaj procesów zarządzanych już przez systemd, kontener lub innego nadzorcę")
+	rulesFile := flag.String("rules", "", "plik reguł filtrów i profili (YAML); pominięte sekcje są brane z reguł wbudowanych")
+	printRules := flag.Bool("print-rules", false, "wypisz wbudowane reguły i zakończ")
+	flag.DurationVar(&opts.observe, "observe", 0, "obserwuj logi wybranych procesów przez podany czas (np. 2m) i wylicz timeout z rytmu zapisów")
+	flag.StringVar(&opts.format, "format", "", "wypisz raport kandydatów i decyzji (json lub table) zamiast tworzyć konfigurację")
+	flag.Var(&opts.imports, "import", "zaimportuj procesy z pliku supervisord .conf, jednostki systemd lub Procfile zamiast wykrywać (można powtarzać)")
+	flag.StringVar(&opts.importFmt, "import-format", "", "format plików --import: supervisord, systemd lub procfile (domyślnie rozpoznawany)")
+	flag.Parse()
+
+	if *printRules {
+		discovery.WriteDefaultRules(os.Stdout)
+		return
+	}
+	if *rulesFile != "" {
+		rules, err := discovery.LoadRules(*rulesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(2)
+		}
+		opts.policy.Rules = rules
+	}
+
+	if opts.format != "" {
+		if err := runReport(&opts, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(opts.selectors) > 0 && opts.output == "" && !opts.yes && !opts.dryRun {
+		fmt.Println("❌ --select wymaga --output, --yes lub --dry-run")
+		os.Exit(2)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+
+	if len(opts.imports) > 0 {
+		configs, err := importProcesses(&opts)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if len(configs) == 0 {
+			os.Exit(1)
+		}
+		writeConfigs(&opts, reader, configs)
+		return
+	}
+
+	fmt.Println("=== MONITOR DISCOVERY ===")
+	fmt.Println("Narzędzie do automatycznego wykrywania procesów do monitorowania")
+	fmt.Println()
+
+	selected := selectProcessesToMonitor(&opts, reader)
+	if len(selected) == 0 {
+		fmt.Println("Nie wybrano żadnych procesów. Zakończenie.")
+		if opts.unattended() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if opts.observe > 0 {
+		discovery.ObserveLogs(selected, opts.observe)
+	}
+
+	configs := discovery.SuggestConfiguration(selected, opts.policy)
+	if len(configs) == 0 && opts.unattended() {
+		os.Exit(1) // Nie nadpisuj istniejącej konfiguracji pustą
+	}
+	writeConfigs(&opts, reader, configs)
+}
+
+// Wypisuje (--dry-run), zapisuje albo scala (--merge) gotową konfigurację
+func writeConfigs(opts *discoveryOptions, reader *bufio.Reader, configs []config.ProcessConfig) {
+	if opts.dryRun {
+		filename := opts.output
+		if filename == "" {
+			filename = defaultOutputFile
+		}
+		if _, err := os.Stat(filename); err == nil && opts.merge {
+			if _, err := mergeIntoFile(opts, reader, configs, filename); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		fmt.Println("\n📄 Konfiguracja (--dry-run, nic nie zapisano):")
+		fmt.Println()
+		discovery.WriteConfiguration(os.Stdout, configs, filename)
+		return
+	}
+
+	filename, ok := chooseOutputFile(opts, reader)
+	if !ok {
+		if opts.unattended() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if _, err := os.Stat(filename); err == nil && opts.merge {
+		saved, err := mergeIntoFile(opts, reader, configs, filename)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if !saved {
+			return
+		}
+	} else if err := discovery.SaveConfiguration(configs, filename); err != nil {
+		fmt.Printf("❌ Błąd zapisu: %v\n", err)
+		os.Exit(1)
+	} else {
+		fmt.Printf("✅ Konfiguracja zapisana do: %s\n", filename)
+	}
+	fmt.Printf("\nUruchom monitor poleceniem:\n")
+	fmt.Printf("./monitor_mutex --config %s\n", filename)
+}
+
+// Tryb --format: wykrywa kandydatów i wypisuje raport zamiast konfiguracji
+func runReport(opts *discoveryOptions, w io.Writer) error {
+	if opts.format != "json" && opts.format != "table" {
+		return fmt.Errorf("nieznany format %q (dostępne: json, table)", opts.format)
+	}
+
+	// Komunikaty postępu na stderr, żeby nie mieszały się z raportem
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	candidates := findCandidates(opts)
+	if len(opts.selectors) > 0 {
+		candidates = opts.selectors.Filter(candidates)
+	}
+	if opts.observe > 0 {
+		discovery.ObserveLogs(candidates, opts.observe)
+	}
+	os.Stdout = stdout
+
+	reports := discovery.BuildReport(candidates, opts.policy)
+	if opts.format == "json" {
+		return discovery.PrintReportJSON(w, reports)
+	}
+	discovery.PrintReportTable(w, reports)
+	return nil
+}
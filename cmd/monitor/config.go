@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"monitor_mutex/config"
+	"os"
+)
+
+// Polecenie `config print` - konfiguracja po podstawieniu zmiennych, defaults i kotwic YAML
+func runConfigCommand(args []string) {
+	if len(args) == 0 || args[0] != "print" {
+		log.Fatalf("Użycie: %s config print [--config <plik.yaml>] [--expand]", os.Args[0])
+	}
+
+	fs := flag.NewFlagSet("config print", flag.ExitOnError)
+	configFile := fs.String("config", defaultConfigFile, "plik konfiguracyjny do wypisania")
+	expand := fs.Bool("expand", false, "wypisz kopie z instances jako osobne procesy")
+	fs.Parse(args[1:])
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatalf("Błąd ładowania konfiguracji: %v", err)
+	}
+
+	if *expand {
+		var processes []config.ProcessConfig
+		for _, pc := range cfg.Processes {
+			instances, err := config.ExpandInstances(pc)
+			if err != nil {
+				log.Fatalf("Błąd konfiguracji procesu %s: %v", pc.Name, err)
+			}
+			processes = append(processes, instances...)
+		}
+		cfg.Processes = processes
+	}
+
+	if err := config.WriteConfig(os.Stdout, cfg); err != nil {
+		log.Fatalf("Błąd: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"monitor_mutex/config"
+	"monitor_mutex/supervisor"
+	"os"
+	"strconv"
+)
+
+// Domyślny plik konfiguracyjny dla poleceń klienckich
+const defaultConfigFile = "monitor_config.yaml"
+
+// Ustala ścieżkę gniazda dla poleceń klienckich (--socket albo z konfiguracji)
+func resolveControlSocket(socketFlag, configFile string) (string, error) {
+	if socketFlag != "" {
+		return socketFlag, nil
+	}
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return "", err
+	}
+	return supervisor.ControlSocketPath(configFile, cfg), nil
+}
+
+// Polecenie `status` - wypisuje stan procesów działającego monitora
+func runStatusCommand(args []string) {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	configFile := fs.String("config", defaultConfigFile, "plik konfiguracyjny działającego monitora")
+	socket := fs.String("socket", "", "ścieżka gniazda sterującego (nadpisuje --config)")
+	asJSON := fs.Bool("json", false, "wypisz status jako JSON")
+	fs.Parse(args)
+
+	path, err := resolveControlSocket(*socket, *configFile)
+	if err != nil {
+		log.Fatalf("Błąd: %v", err)
+	}
+
+	statuses, err := supervisor.SendCommand(path, "status", "")
+	if err != nil {
+		log.Fatalf("Błąd: %v", err)
+	}
+
+	if *asJSON {
+		if err := supervisor.PrintStatusJSON(os.Stdout, statuses); err != nil {
+			log.Fatalf("Błąd: %v", err)
+		}
+		return
+	}
+	supervisor.PrintStatusTable(os.Stdout, statuses)
+}
+
+// Polecenia `restart|stop|start <nazwa>` - ręczne sterowanie procesem działającego monitora
+func runActionCommand(action string, args []string) {
+	fs := flag.NewFlagSet(action, flag.ExitOnError)
+	configFile := fs.String("config", defaultConfigFile, "plik konfiguracyjny działającego monitora")
+	socket := fs.String("socket", "", "ścieżka gniazda sterującego (nadpisuje --config)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		log.Fatalf("Użycie: %s %s [--config <plik.yaml>] <nazwa_procesu>", os.Args[0], action)
+	}
+
+	path, err := resolveControlSocket(*socket, *configFile)
+	if err != nil {
+		log.Fatalf("Błąd: %v", err)
+	}
+
+	statuses, err := supervisor.SendCommand(path, action, fs.Arg(0))
+	if err != nil {
+		log.Fatalf("Błąd: %v", err)
+	}
+	supervisor.PrintStatusTable(os.Stdout, statuses)
+}
+
+// Polecenie `scale <nazwa> <liczba>` - zmienia liczbę kopii wpisu z instances
+func runScaleCommand(args []string) {
+	fs := flag.NewFlagSet("scale", flag.ExitOnError)
+	configFile := fs.String("config", defaultConfigFile, "plik konfiguracyjny działającego monitora")
+	socket := fs.String("socket", "", "ścieżka gniazda sterującego (nadpisuje --config)")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		log.Fatalf("Użycie: %s scale [--config <plik.yaml>] <nazwa_procesu> <liczba_kopii>", os.Args[0])
+	}
+	count, err := strconv.Atoi(fs.Arg(1))
+	if err != nil || count < 0 {
+		log.Fatalf("Błąd: nieprawidłowa liczba kopii %q", fs.Arg(1))
+	}
+
+	path, err := resolveControlSocket(*socket, *configFile)
+	if err != nil {
+		log.Fatalf("Błąd: %v", err)
+	}
+
+	statuses, err := supervisor.SendScale(path, fs.Arg(0), count)
+	if err != nil {
+		log.Fatalf("Błąd: %v", err)
+	}
+	supervisor.PrintStatusTable(os.Stdout, statuses)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"monitor_mutex/config"
+	"monitor_mutex/supervisor"
+	"os"
+	"path/filepath"
+)
+
+// Polecenie `export systemd` - jednostki systemd z pliku konfiguracyjnego
+func runExportCommand(args []string) {
+	if len(args) == 0 || args[0] != "systemd" {
+		log.Fatalf("Użycie: %s export systemd [--config <plik.yaml>] [--dir <katalog>] [--self]", os.Args[0])
+	}
+
+	fs := flag.NewFlagSet("export systemd", flag.ExitOnError)
+	configFile := fs.String("config", defaultConfigFile, "plik konfiguracyjny do przełożenia na jednostki")
+	dir := fs.String("dir", "", "zapisz pliki .service w katalogu zamiast wypisywać je na stdout")
+	self := fs.Bool("self", false, "jedna jednostka dla monitora zamiast jednostek dla procesów")
+	fs.Parse(args[1:])
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatalf("Błąd ładowania konfiguracji: %v", err)
+	}
+
+	var units []supervisor.SystemdUnit
+	if *self {
+		executable, err := os.Executable()
+		if err != nil {
+			log.Fatalf("Błąd: nie można ustalić ścieżki programu: %v", err)
+		}
+		absConfig, err := filepath.Abs(*configFile)
+		if err != nil {
+			log.Fatalf("Błąd: %v", err)
+		}
+		units = append(units, supervisor.SupervisorUnit(executable, absConfig))
+	} else {
+		for _, pc := range cfg.Processes {
+			// Każda kopia z instances dostaje własną jednostkę z wypełnionymi szablonami
+			instances, err := config.ExpandInstances(pc)
+			if err != nil {
+				log.Fatalf("Błąd konfiguracji procesu %s: %v", pc.Name, err)
+			}
+			for _, instance := range instances {
+				units = append(units, supervisor.SystemdUnitFor(instance))
+			}
+		}
+		if len(cfg.Notifications.Webhooks) > 0 || cfg.Notifications.OnRestart != "" || cfg.Notifications.OnFailure != "" {
+			fmt.Fprintln(os.Stderr, "Uwaga: globalne notifications nie mają odpowiednika w jednostkach - użyj OnFailure=")
+		}
+	}
+
+	for i, unit := range units {
+		// Ostrzeżenia na stderr, żeby nie mieszały się z jednostkami na stdout
+		for _, note := range unit.Unmapped {
+			fmt.Fprintf(os.Stderr, "Uwaga: %s: %s\n", unit.Name, note)
+		}
+
+		if *dir != "" {
+			path := filepath.Join(*dir, unit.Name)
+			if err := os.WriteFile(path, []byte(unit.Content), 0644); err != nil {
+				log.Fatalf("Błąd zapisu %s: %v", path, err)
+			}
+			fmt.Printf("Zapisano %s\n", path)
+			continue
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("# ===== %s =====\n", unit.Name)
+		fmt.Print(unit.Content)
+	}
+}
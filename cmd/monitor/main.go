@@ -0,0 +1,203 @@
+// Program monitor_mutex - restartuje procesy, których logi przestały się zmieniać
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"monitor_mutex/config"
+	"monitor_mutex/supervisor"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+)
+
+// Uruchom monitorowanie z pliku konfiguracyjnego
+func runFromConfig(configFile string, useTUI bool) {
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		log.Fatalf("Błąd ładowania konfiguracji: %v", err)
+	}
+
+	if len(cfg.Processes) == 0 {
+		log.Fatal("Brak procesów do monitorowania w konfiguracji")
+	}
+
+	sup, err := supervisor.New(configFile, cfg)
+	if err != nil {
+		log.Fatalf("Błąd: %v", err)
+	}
+
+	// Wpisy z instances dają po kilka procesów
+	fmt.Printf("Uruchamianie monitora z %d procesami z pliku: %s\n", len(sup.Monitors()), configFile)
+
+	// Sygnał nie kończy programu od razu - monitory muszą zatrzymać procesy i zapisać stan
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigChan
+		fmt.Printf("\nOtrzymano sygnał %v, zamykanie wszystkich monitorów...\n", sig)
+		cancel()
+	}()
+
+	// Dashboard przechwytuje komunikaty monitorów, więc musi wystartować przed nimi
+	var dash *supervisor.Dashboard
+	if useTUI {
+		dash, err = supervisor.StartDashboard(sup.Monitors)
+		if err != nil {
+			log.Fatalf("Błąd uruchamiania dashboardu: %v", err)
+		}
+		defer dash.Close()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		if err := sup.Run(ctx); err != nil {
+			log.Printf("Błąd: %v", err)
+		}
+		close(done)
+	}()
+
+	if dash != nil {
+		dash.Run(done, cancel)
+	}
+	<-done
+}
+
+// Wyświetla instrukcję użycia
+func printUsage(progName string) {
+	fmt.Printf("Monitor Procesów - automatyczny restart przy braku aktywności\n\n")
+	fmt.Printf("Użycie:\n")
+	fmt.Printf("  %s --config <plik.yaml> [--tui]            # Monitor z pliku YAML (--tui: dashboard)\n", progName)
+	fmt.Printf("  %s <komenda> <plik_logów> [timeout] [interwał]  # Monitor pojedynczy\n", progName)
+	fmt.Printf("  %s status [--config <plik.yaml>] [--json]   # Stan procesów działającego monitora\n", progName)
+	fmt.Printf("  %s restart|stop|start <nazwa>               # Ręczne sterowanie procesem\n", progName)
+	fmt.Printf("  %s scale <nazwa> <liczba>                   # Liczba kopii procesu z instances\n", progName)
+	fmt.Printf("  %s export systemd [--dir <katalog>] [--self] # Jednostki systemd z konfiguracji\n", progName)
+	fmt.Printf("  %s config print [--expand]                  # Konfiguracja po podstawieniu zmiennych i defaults\n\n", progName)
+	fmt.Printf("Parametry trybu pojedynczego:\n")
+	fmt.Printf("  komenda      - aplikacja do monitorowania (w cudzysłowach)\n")
+	fmt.Printf("  plik_logów   - ścieżka do pliku z logami\n")
+	fmt.Printf("  timeout_sek  - restart po X sekundach bez zmian (domyślnie: 60)\n")
+	fmt.Printf("  interwał_sek - sprawdzaj co X sekund (domyślnie: 5)\n\n")
+	fmt.Printf("Przykład pliku YAML:\n")
+	fmt.Printf("  processes:\n")
+	fmt.Printf("    - name: \"WebServer\"\n")
+	fmt.Printf("      command: \"python3 app.py\"\n")
+	fmt.Printf("      log_file: \"/tmp/app.log\"\n")
+	fmt.Printf("      timeout: 60\n")
+	fmt.Printf("      interval: 5\n")
+	fmt.Printf("      restart_schedule: \"0 3 * * *\"   # opcjonalnie: restart codziennie o 3:00\n")
+	fmt.Printf("      maintenance_windows:            # opcjonalnie: bez restartów z powodu ciszy w logach\n")
+	fmt.Printf("        - start: \"0 1 * * *\"\n")
+	fmt.Printf("          duration: 3600\n\n")
+	fmt.Printf("Zmienne w pliku YAML:\n")
+	fmt.Printf("  ${NAZWA} i ${NAZWA:-domyślna} monitor podstawia ze swojego środowiska; nieustawiona\n")
+	fmt.Printf("  zmienna bez domyślnej wartości to błąd. Zmienne, które ma rozwinąć powłoka procesu\n")
+	fmt.Printf("  (np. w istniejącej komendzie sh -c), zapisz jako $${NAZWA}.\n\n")
+	fmt.Printf("Przykłady użycia:\n")
+	fmt.Printf("  %s --config monitor_config.yaml\n", progName)
+	fmt.Printf("  %s \"python3 app.py > /tmp/app.log 2>&1\" \"/tmp/app.log\"\n", progName)
+	fmt.Printf("  %s \"java -jar app.jar\" \"/var/log/app.log\" 120 10\n", progName)
+}
+
+func main() {
+	// Sprawdzenie argumentów
+	if len(os.Args) < 2 {
+		printUsage(os.Args[0])
+		os.Exit(1)
+	}
+
+	// Tryb z plikiem konfiguracyjnym
+	if os.Args[1] == "--config" {
+		if len(os.Args) < 3 {
+			fmt.Println("Błąd: Brak ścieżki do pliku konfiguracyjnego")
+			printUsage(os.Args[0])
+			os.Exit(1)
+		}
+
+		useTUI := len(os.Args) > 3 && os.Args[3] == "--tui"
+		runFromConfig(os.Args[2], useTUI)
+		return
+	}
+
+	// Polecenia klienckie działające na uruchomionym monitorze
+	switch os.Args[1] {
+	case "status":
+		runStatusCommand(os.Args[2:])
+		return
+	case supervisor.ActionRestart, supervisor.ActionStop, supervisor.ActionStart:
+		runActionCommand(os.Args[1], os.Args[2:])
+		return
+	case supervisor.ActionScale:
+		runScaleCommand(os.Args[2:])
+		return
+	case "export":
+		runExportCommand(os.Args[2:])
+		return
+	case "config":
+		runConfigCommand(os.Args[2:])
+		return
+	}
+
+	// Tryb pojedynczego procesu - sprawdzenie argumentów
+	if len(os.Args) < 3 {
+		printUsage(os.Args[0])
+		os.Exit(1)
+	}
+
+	// Parsowanie argumentów
+	command := os.Args[1]
+	logFile := os.Args[2]
+
+	// Domyślne wartości
+	timeout := 60 // 60 sekund timeout
+	interval := 5 // sprawdzaj co 5 sekund
+
+	// Opcjonalne argumenty
+	if len(os.Args) > 3 {
+		if t, err := strconv.Atoi(os.Args[3]); err == nil && t > 0 {
+			timeout = t
+		} else {
+			fmt.Printf("Nieprawidłowy timeout '%s', używam domyślnego: %d\n", os.Args[3], timeout)
+		}
+	}
+
+	if len(os.Args) > 4 {
+		if i, err := strconv.Atoi(os.Args[4]); err == nil && i > 0 {
+			interval = i
+		} else {
+			fmt.Printf("Nieprawidłowy interwał '%s', używam domyślnego: %d\n", os.Args[4], interval)
+		}
+	}
+
+	// Walidacja parametrów
+	if timeout < interval {
+		fmt.Printf("Timeout (%d) jest mniejszy niż interwał (%d), może prowadzić do częstych restartów\n", timeout, interval)
+	}
+
+	if interval < 1 {
+		fmt.Printf("Interwał (%d) jest zbyt mały, ustawiam minimum 1 sekunda\n", interval)
+		interval = 1
+	}
+
+	// Utworzenie i uruchomienie monitora
+	monitor := supervisor.NewMonitor(command, logFile, timeout, interval)
+
+	// Obsługa sygnałów systemowych (Ctrl+C, kill)
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigChan
+		fmt.Printf("\nOtrzymano sygnał %v, zamykanie monitora...\n", sig)
+		monitor.Shutdown()
+	}()
+
+	fmt.Println("Aby zatrzymać monitor, naciśnij Ctrl+C")
+	if err := monitor.Run(); err != nil {
+		log.Fatalf("Błąd: %v", err)
+	}
+}
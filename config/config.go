@@ -0,0 +1,168 @@
+// Pakiet config zawiera format pliku konfiguracyjnego wspólny dla monitor_mutex
+// (odczyt) i discovery (zapis), żeby oba narzędzia nie rozjeżdżały się.
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Konfiguracja z pliku YAML
+type Config struct {
+	Processes     []ProcessConfig    `yaml:"processes"`
+	Defaults      *ProcessConfig     `yaml:"defaults,omitempty"`       // Wartości dla pól, których wpis w processes nie ustawia
+	Notifications NotificationConfig `yaml:"notifications,omitempty"`  // Powiadomienia wspólne dla wszystkich procesów
+	StateFile     string             `yaml:"state_file,omitempty"`     // Plik stanu (domyślnie monitor_state.json obok konfiguracji)
+	ControlSocket string             `yaml:"control_socket,omitempty"` // Gniazdo sterujące (domyślnie monitor_mutex.sock obok konfiguracji)
+}
+
+type ProcessConfig struct {
+	Name               string                    `yaml:"name"`
+	Command            string                    `yaml:"command"`
+	LogFile            string                    `yaml:"log_file"`
+	Timeout            int                       `yaml:"timeout"`
+	Interval           int                       `yaml:"interval"`
+	Instances          int                       `yaml:"instances,omitempty"`           // Liczba kopii z szablonami {{.Index}} (0 = jeden proces bez szablonów)
+	WorkingDir         string                    `yaml:"working_dir,omitempty"`         // Katalog roboczy procesu
+	Env                []string                  `yaml:"env,omitempty"`                 // Dodatkowe zmienne środowiska KLUCZ=wartość
+	User               string                    `yaml:"user,omitempty"`                // Uruchom jako ten użytkownik (wymaga roota)
+	Group              string                    `yaml:"group,omitempty"`               // Grupa (domyślnie główna grupa użytkownika)
+	Stdout             string                    `yaml:"stdout,omitempty"`              // Plik, do którego dopisywane jest stdout
+	Stderr             string                    `yaml:"stderr,omitempty"`              // Plik dla stderr (ten sam co stdout = 2>&1)
+	RestartSchedule    string                    `yaml:"restart_schedule,omitempty"`    // Cron - zaplanowane restarty
+	MaintenanceWindows []MaintenanceWindowConfig `yaml:"maintenance_windows,omitempty"` // Okna bez restartów z powodu ciszy w logach
+	AdaptiveTimeout    *AdaptiveTimeoutConfig    `yaml:"adaptive_timeout,omitempty"`    // Timeout wyuczony z przerw między zapisami do logu
+	Watchdog           *WatchdogConfig           `yaml:"watchdog,omitempty"`            // Gotowość i życie zgłaszane przez aplikację (sd_notify, plik heartbeat)
+	Notifications      *NotificationConfig       `yaml:"notifications,omitempty"`       // Uzupełnia/nadpisuje powiadomienia globalne
+}
+
+// Okno serwisowe: początek w formacie cron i długość w sekundach
+type MaintenanceWindowConfig struct {
+	Start    string `yaml:"start"`
+	Duration int    `yaml:"duration"`
+}
+
+// Adaptacyjny timeout: granice i sposób liczenia z historii przerw w logach
+type AdaptiveTimeoutConfig struct {
+	Min        int     `yaml:"min,omitempty"`         // Dolna granica w sekundach (domyślnie 3 × interval)
+	Max        int     `yaml:"max,omitempty"`         // Górna granica w sekundach (domyślnie 10 × timeout)
+	Percentile int     `yaml:"percentile,omitempty"`  // Percentyl przerw uznawany za normalną ciszę (domyślnie 99)
+	Margin     float64 `yaml:"margin,omitempty"`      // Mnożnik percentyla (domyślnie 2)
+	History    int     `yaml:"history,omitempty"`     // Ile ostatnich przerw pamiętać (domyślnie 500)
+	MinSamples int     `yaml:"min_samples,omitempty"` // Do tylu przerw obowiązuje stały timeout (domyślnie 20)
+}
+
+// Sygnały od aplikacji: gniazdo zgodne z sd_notify albo plik dotykany przez aplikację
+type WatchdogConfig struct {
+	Notify        bool   `yaml:"notify,omitempty"`         // Przekaż NOTIFY_SOCKET i przyjmuj READY=1, WATCHDOG=1, STATUS=...
+	HeartbeatFile string `yaml:"heartbeat_file,omitempty"` // Plik, którego czas modyfikacji jest sygnałem życia
+	Timeout       int    `yaml:"timeout,omitempty"`        // Sekundy bez sygnału życia do restartu (0 = bez kontroli życia)
+	ReadyTimeout  int    `yaml:"ready_timeout,omitempty"`  // Sekundy od startu na READY=1 (0 = nie czekaj)
+}
+
+// Konfiguracja powiadomień (globalna lub dla pojedynczego procesu)
+type NotificationConfig struct {
+	Webhooks    []WebhookConfig `yaml:"webhooks,omitempty"`
+	OnRestart   string          `yaml:"on_restart,omitempty"`   // Komenda shell uruchamiana po restarcie
+	OnFailure   string          `yaml:"on_failure,omitempty"`   // Komenda shell uruchamiana po wyczerpaniu prób
+	DedupWindow int             `yaml:"dedup_window,omitempty"` // Sekundy, w których identyczne zdarzenie jest pomijane
+	MaxPerHour  int             `yaml:"max_per_hour,omitempty"` // Limit powiadomień na godzinę dla każdego procesu osobno (0 = bez limitu)
+}
+
+// Konfiguracja pojedynczego webhooka HTTP
+type WebhookConfig struct {
+	URL     string            `yaml:"url"`
+	Method  string            `yaml:"method,omitempty"`  // Domyślnie POST
+	Headers map[string]string `yaml:"headers,omitempty"` // Dodatkowe nagłówki
+	Body    string            `yaml:"body,omitempty"`    // Szablon text/template (domyślnie JSON zdarzenia)
+	Events  []string          `yaml:"events,omitempty"`  // Filtr zdarzeń (puste = wszystkie)
+	Retries int               `yaml:"retries,omitempty"` // Liczba ponowień (domyślnie 3)
+	Timeout int               `yaml:"timeout,omitempty"` // Timeout żądania w sekundach (domyślnie 10)
+}
+
+// Wczytuje konfigurację z pliku
+func Load(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("nie można odczytać pliku %s: %v", filename, err)
+	}
+	return Parse(data)
+}
+
+// Parsuje konfigurację z YAML: podstawia ${ZMIENNE} ze środowiska i uzupełnia procesy z defaults
+func Parse(data []byte) (*Config, error) {
+	// Mapy, nie yaml.MapSlice - dekodowanie do MapSlice gubi scalanie <<: *kotwica
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("błąd parsowania YAML: %v", err)
+	}
+	resolved, err := interpolateEnv(doc, "", os.LookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("błąd podstawiania zmiennych: %v", err)
+	}
+
+	// Dokument z podstawionymi wartościami jest kodowany ponownie - yaml.Marshal sam
+	// dobiera cudzysłowy, więc wartość zmiennej pozostaje jednym napisem
+	data, err = yaml.Marshal(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("błąd podstawiania zmiennych: %v", err)
+	}
+	return ParseRaw(data)
+}
+
+// Parsuje konfigurację bez podstawiania zmiennych - ${...} i $${ zostają w polach dosłownie
+//
+// Dla narzędzi, które tylko czytają i zmieniają plik (discovery --merge), więc nie
+// powinny wymagać zmiennych ustawionych dla monitora.
+func ParseRaw(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("błąd parsowania YAML: %v", err)
+	}
+
+	if cfg.Defaults != nil {
+		if cfg.Defaults.Name != "" {
+			return nil, fmt.Errorf("defaults: name trzeba podać w każdym procesie")
+		}
+		for i := range cfg.Processes {
+			applyDefaults(&cfg.Processes[i], *cfg.Defaults)
+		}
+	}
+	return &cfg, nil
+}
+
+// Zapisuje listę processes jako YAML (wpisy rozdzielone pustą linią dla czytelności)
+func WriteProcesses(w io.Writer, processes []ProcessConfig) error {
+	if _, err := fmt.Fprintln(w, "processes:"); err != nil {
+		return err
+	}
+	for _, pc := range processes {
+		lines, err := MarshalProcess(pc, "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s\n\n", strings.Join(lines, "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Koduje pojedynczy wpis listy processes ("- name: ...") z podanym wcięciem myślnika
+func MarshalProcess(pc ProcessConfig, indent string) ([]string, error) {
+	data, err := yaml.Marshal([]ProcessConfig{pc})
+	if err != nil {
+		return nil, err
+	}
+
+	// Dosłowne ${ nie może zostać rozwinięte przy wczytaniu
+	lines := strings.Split(strings.TrimSuffix(escapeEnv(string(data)), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = indent + line
+	}
+	return lines, nil
+}
@@ -0,0 +1,337 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Wpisy z wartościami, które ręcznie pisany YAML psuł (cudzysłowy, ukośniki, znaki specjalne)
+var trickyProcesses = []ProcessConfig{
+	{
+		Name:     "web",
+		Command:  "/usr/bin/python3 -m http.server 8080",
+		LogFile:  "/var/log/web.log",
+		Timeout:  120,
+		Interval: 10,
+	},
+	{
+		Name:     `quoted "app"`,
+		Command:  `sh -c "echo \"hello\" >> /tmp/app.log 2>&1"`,
+		LogFile:  `C:\logs\app.log`,
+		Timeout:  30,
+		Interval: 5,
+	},
+	{
+		Name:     "yes",
+		Command:  "- not a list: # not a comment",
+		LogFile:  "/tmp/zażółć gęślą jaźń.log",
+		Timeout:  45,
+		Interval: 8,
+	},
+	{
+		Name:     "0123",
+		Command:  "bash -c 'while true; do\n  date\n  sleep 1\ndone'",
+		LogFile:  "/tmp/multi.log",
+		Timeout:  60,
+		Interval: 5,
+	},
+}
+
+// Zapis discovery (WriteProcesses z nagłówkiem) wczytany przez Load daje te same wartości
+func TestWriteProcessesRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("# Automatycznie wygenerowana konfiguracja monitora\n\n")
+	if err := WriteProcesses(&buf, trickyProcesses); err != nil {
+		t.Fatalf("WriteProcesses: %v", err)
+	}
+	buf.WriteString("# Uruchom monitor poleceniem:\n")
+
+	path := filepath.Join(t.TempDir(), "monitor_config.yaml")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v\n%s", err, buf.String())
+	}
+	if !reflect.DeepEqual(cfg.Processes, trickyProcesses) {
+		t.Errorf("wczytano inne wartości\nzapisano: %#v\nwczytano: %#v\nplik:\n%s", trickyProcesses, cfg.Processes, buf.String())
+	}
+}
+
+// Wpisy z MarshalProcess dopisane z dowolnym wcięciem (tryb --merge) parsują się identycznie
+func TestMarshalProcessRoundTrip(t *testing.T) {
+	for _, indent := range []string{"", "  ", "    "} {
+		lines := []string{"processes:"}
+		for _, pc := range trickyProcesses {
+			entry, err := MarshalProcess(pc, indent)
+			if err != nil {
+				t.Fatalf("MarshalProcess: %v", err)
+			}
+			lines = append(lines, entry...)
+		}
+		data := strings.Join(lines, "\n") + "\n"
+
+		cfg, err := Parse([]byte(data))
+		if err != nil {
+			t.Fatalf("wcięcie %q: Parse: %v\n%s", indent, err, data)
+		}
+		if !reflect.DeepEqual(cfg.Processes, trickyProcesses) {
+			t.Errorf("wcięcie %q: wczytano inne wartości\n%s", indent, data)
+		}
+	}
+}
+
+// Pola opcjonalne przechodzą przez zapis i odczyt, a puste nie trafiają do pliku
+func TestOptionalFieldsRoundTrip(t *testing.T) {
+	full := ProcessConfig{
+		Name:            "worker",
+		Command:         "/opt/worker/run",
+		LogFile:         "/var/log/worker.log",
+		Timeout:         90,
+		Interval:        10,
+		Instances:       2,
+		WorkingDir:      "/opt/worker",
+		Env:             []string{"PORT=8080", "APP_ENV=prod mode"},
+		User:            "worker",
+		Group:           "www-data",
+		Stdout:          "/var/log/worker.log",
+		Stderr:          "/var/log/worker.err",
+		RestartSchedule: "0 4 * * *",
+		MaintenanceWindows: []MaintenanceWindowConfig{
+			{Start: "0 2 * * 0", Duration: 3600},
+		},
+		AdaptiveTimeout: &AdaptiveTimeoutConfig{Min: 30, Max: 900, Percentile: 95, Margin: 1.5},
+		Watchdog:        &WatchdogConfig{Notify: true, Timeout: 60, ReadyTimeout: 30},
+		Notifications: &NotificationConfig{
+			Webhooks:  []WebhookConfig{{URL: "https://example.com/hook", Events: []string{"failure"}}},
+			OnFailure: "/usr/local/bin/alert",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteProcesses(&buf, []ProcessConfig{full, trickyProcesses[0]}); err != nil {
+		t.Fatalf("WriteProcesses: %v", err)
+	}
+
+	cfg, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Processes, []ProcessConfig{full, trickyProcesses[0]}) {
+		t.Errorf("wczytano inne wartości:\n%s", buf.String())
+	}
+
+	minimal, err := MarshalProcess(trickyProcesses[0], "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"instances", "working_dir", "env", "user", "group", "stdout", "stderr", "restart_schedule", "maintenance_windows", "adaptive_timeout", "watchdog", "notifications"} {
+		if strings.Contains(strings.Join(minimal, "\n"), key) {
+			t.Errorf("puste pole %s zapisane do pliku:\n%s", key, strings.Join(minimal, "\n"))
+		}
+	}
+}
+
+// Kopie z instances dostają własne nazwy, pliki i zmienne z szablonów
+func TestExpandInstances(t *testing.T) {
+	pc := ProcessConfig{
+		Name:     "worker",
+		Command:  "/opt/worker/run --id {{.Index}}",
+		LogFile:  "/var/log/{{.Name}}-{{.Index}}.log",
+		Timeout:  60,
+		Interval: 5,
+		Env:      []string{"PORT={{add 8000 .Index}}", "WORKERS={{.Instances}}"},
+		Watchdog: &WatchdogConfig{HeartbeatFile: "/run/worker-{{.Index}}.hb", Timeout: 30},
+		Notifications: &NotificationConfig{
+			Webhooks: []WebhookConfig{{URL: "https://example.com/hook", Body: `{"text": {{json .Reason}}}`}},
+		},
+		Instances: 3,
+	}
+
+	copies, err := ExpandInstances(pc)
+	if err != nil {
+		t.Fatalf("ExpandInstances: %v", err)
+	}
+	if len(copies) != 3 {
+		t.Fatalf("%d kopii, oczekiwano 3", len(copies))
+	}
+	last := copies[2]
+	if last.Name != "worker-2" || last.Command != "/opt/worker/run --id 2" || last.LogFile != "/var/log/worker-2.log" || last.Instances != 0 {
+		t.Errorf("kopia 2: %+v", last)
+	}
+	if !reflect.DeepEqual(last.Env, []string{"PORT=8002", "WORKERS=3"}) {
+		t.Errorf("env %q", last.Env)
+	}
+	if last.Watchdog.HeartbeatFile != "/run/worker-2.hb" || copies[0].Watchdog.HeartbeatFile != "/run/worker-0.hb" {
+		t.Errorf("heartbeat_file %q, %q", copies[0].Watchdog.HeartbeatFile, last.Watchdog.HeartbeatFile)
+	}
+	// Szablony webhooków wypełnia notifier, nie instances
+	if last.Notifications.Webhooks[0].Body != pc.Notifications.Webhooks[0].Body {
+		t.Errorf("body webhooka zmienione: %q", last.Notifications.Webhooks[0].Body)
+	}
+	if pc.Env[0] != "PORT={{add 8000 .Index}}" {
+		t.Errorf("rozwinięcie zmieniło wpis z konfiguracji: %q", pc.Env)
+	}
+
+	named := pc
+	named.Name = "queue_{{add 1 .Index}}"
+	if copies, err := ExpandInstances(named); err != nil || copies[0].Name != "queue_1" {
+		t.Errorf("nazwa z szablonu: %v %+v", err, copies)
+	}
+
+	// Wpis bez instances zostaje bez zmian, nawet jeśli komenda zawiera {{
+	plain := ProcessConfig{Name: "docker", Command: "docker ps --format '{{.Names}}'", LogFile: "/tmp/d.log"}
+	if copies, err := ExpandInstances(plain); err != nil || !reflect.DeepEqual(copies, []ProcessConfig{plain}) {
+		t.Errorf("wpis bez instances: %v %+v", err, copies)
+	}
+
+	for name, bad := range map[string]ProcessConfig{
+		"wspólny log_file": {Name: "w", Command: "run", LogFile: "/tmp/w.log", Instances: 2},
+		"nieznane pole":    {Name: "w", Command: "run {{.Port}}", LogFile: "/tmp/w-{{.Index}}.log", Instances: 2},
+		"błąd składni":     {Name: "w", Command: "run {{.Index", LogFile: "/tmp/w-{{.Index}}.log", Instances: 2},
+		"ujemna liczba":    {Name: "w", Command: "run", LogFile: "/tmp/w-{{.Index}}.log", Instances: -1},
+		"stała nazwa":      {Name: "w{{if false}}{{end}}", Command: "run", LogFile: "/tmp/w-{{.Index}}.log", Instances: 2},
+	} {
+		if _, err := ExpandInstances(bad); err == nil {
+			t.Errorf("%s: oczekiwano błędu", name)
+		}
+	}
+}
+
+// Zmienne środowiska, defaults i kotwice YAML rozwiązywane przy wczytaniu
+func TestParseResolvesConfig(t *testing.T) {
+	t.Setenv("LOG_DIR", "/var/log/app")
+	t.Setenv("EMPTY", "")
+	t.Setenv("QUOTE", `a"b`)
+
+	data := []byte(`# Komentarz z ${NIEUSTAWIONA} nie jest rozwijany
+x-common: &common # ani komentarz na końcu linii: ${NIEUSTAWIONA}
+  working_dir: /srv/app
+  env: ["FROM_ANCHOR=1"]
+
+defaults:
+  timeout: 60
+  interval: 5
+  env: ["APP_ENV=${APP_ENV:-prod}", "PORT=8000"]
+  watchdog:
+    heartbeat_file: /run/app.hb
+    timeout: 30
+
+processes:
+  - <<: *common
+    name: web
+    command: "python3 web.py --level ${EMPTY:-info} --literal $${HOME}"
+    log_file: "${LOG_DIR}/web.log"
+    env: ["PORT=8080"]
+  - name: worker
+    command: 'echo ${QUOTE} # bez komentarza' # kolejne ${NIEUSTAWIONA}
+    log_file: ${LOG_DIR}/worker.log
+    timeout: 120
+`)
+
+	cfg, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	web, worker := cfg.Processes[0], cfg.Processes[1]
+	if web.Command != "python3 web.py --level info --literal ${HOME}" || web.LogFile != "/var/log/app/web.log" || web.WorkingDir != "/srv/app" {
+		t.Errorf("web: %+v", web)
+	}
+	// Klucz wpisu wygrywa z kotwicą (<<), a env z defaults trafia na początek listy
+	if want := []string{"APP_ENV=prod", "PORT=8000", "PORT=8080"}; !reflect.DeepEqual(web.Env, want) {
+		t.Errorf("web env %q, oczekiwano %q", web.Env, want)
+	}
+	if worker.Command != `echo a"b # bez komentarza` || worker.Timeout != 120 || worker.Interval != 5 || worker.WorkingDir != "" {
+		t.Errorf("worker: %+v", worker)
+	}
+	if web.Watchdog == nil || worker.Watchdog == nil || web.Watchdog == worker.Watchdog || web.Watchdog.Timeout != 30 {
+		t.Errorf("watchdog z defaults: %+v, %+v", web.Watchdog, worker.Watchdog)
+	}
+
+	// Wypisana konfiguracja wczytuje się do tych samych wartości, także z dosłownym ${
+	var buf bytes.Buffer
+	if err := WriteConfig(&buf, cfg); err != nil {
+		t.Fatalf("WriteConfig: %v", err)
+	}
+	printed, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Parse wypisanej konfiguracji: %v\n%s", err, buf.String())
+	}
+	if !reflect.DeepEqual(printed.Processes, cfg.Processes) {
+		t.Errorf("wypisana konfiguracja wczytuje się inaczej:\n%s", buf.String())
+	}
+
+	for name, bad := range map[string]string{
+		"nieustawiona zmienna": "processes:\n  - name: ${NIEUSTAWIONA_ZMIENNA}\n",
+		"nieprawidłowa nazwa":  "processes:\n  - name: ${1abc}\n",
+		"operacja powłoki":     "processes:\n  - command: echo ${LOG_DIR#/var}\n",
+		"name w defaults":      "defaults:\n  name: x\nprocesses: []\n",
+	} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("%s: oczekiwano błędu", name)
+		}
+	}
+
+	// ParseRaw zostawia wyrażenia dosłownie, więc nieustawiona zmienna nie jest błędem
+	raw, err := ParseRaw([]byte("processes:\n  - name: app\n    command: sh -c 'echo ${NIEUSTAWIONA_ZMIENNA}'\n"))
+	if err != nil || raw.Processes[0].Command != "sh -c 'echo ${NIEUSTAWIONA_ZMIENNA}'" {
+		t.Errorf("ParseRaw: %v %+v", err, raw)
+	}
+}
+
+// Wartość zmiennej zostaje jednym napisem - nie zmienia struktury dokumentu
+func TestParseEnvInjection(t *testing.T) {
+	t.Setenv("BLOCK_VAR", "w bloku")
+	t.Setenv("VERSION", "1.10")
+	t.Setenv("ZEROS", "007")
+
+	tests := []struct {
+		msg  string
+		yaml string
+		want string
+	}{
+		{"x\n    user: root", "command: run --msg ${MSG}", "run --msg x\n    user: root"},
+		{"a #b", "command: run --msg ${MSG}", "run --msg a #b"},
+		{`hi"there`, `command: "echo ${MSG}"`, `echo hi"there`},
+		{"it's", "command: 'echo ${MSG}'", "echo it's"},
+		{"[1, 2]", "command: ${MSG}", "[1, 2]"},
+		// W bloku | znak # jest treścią, nie komentarzem
+		{"b", "command: |\n      echo ${MSG}\n      # ${BLOCK_VAR}\n", "echo b\n# w bloku\n"},
+		{"b", "command: >\n      echo ${MSG} #${BLOCK_VAR}\n", "echo b #w bloku\n"},
+		// Liczby tylko w postaci kanonicznej - napis nie traci zer ani cyfr
+		{"", "command: ${ZEROS}", "007"},
+		{"", "command: ${VERSION}", "1.10"},
+	}
+	for _, tt := range tests {
+		t.Setenv("MSG", tt.msg)
+		cfg, err := Parse([]byte("processes:\n  - name: app\n    " + tt.yaml + "\n"))
+		if err != nil {
+			t.Errorf("%q z MSG=%q: %v", tt.yaml, tt.msg, err)
+			continue
+		}
+		pc := cfg.Processes[0]
+		if pc.Command != tt.want || pc.User != "" || len(cfg.Processes) != 1 {
+			t.Errorf("%q z MSG=%q: command %q, user %q, oczekiwano %q", tt.yaml, tt.msg, pc.Command, pc.User, tt.want)
+		}
+	}
+
+	t.Setenv("TIMEOUT", "90")
+	cfg, err := Parse([]byte("processes:\n  - name: app\n    timeout: ${TIMEOUT}\n    interval: ${INTERVAL:-5}\n    adaptive_timeout:\n      margin: ${MARGIN:-1.5}\n"))
+	if err != nil {
+		t.Fatalf("liczby ze zmiennych: %v", err)
+	}
+	if pc := cfg.Processes[0]; pc.Timeout != 90 || pc.Interval != 5 || pc.AdaptiveTimeout.Margin != 1.5 {
+		t.Errorf("liczby ze zmiennych: %+v %+v", pc, pc.AdaptiveTimeout)
+	}
+
+	_, err = Parse([]byte("processes:\n  - name: app\n    env: [\"A=1\", \"B=${NIEUSTAWIONA_ZMIENNA}\"]\n"))
+	if err == nil || !strings.Contains(err.Error(), "processes[0].env[1]") {
+		t.Errorf("błąd bez ścieżki pola: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Zapisuje plik atomowo: plik tymczasowy w tym samym katalogu + rename
+//
+// Przerwany zapis zostawia poprzednią zawartość - używane dla pliku stanu monitora
+// i scalanej konfiguracji.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // Po udanym rename nic nie usuwa
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
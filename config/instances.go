@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Dane dostępne w szablonach wpisu z instances
+type InstanceData struct {
+	Index     int    // Numer kopii od 0
+	Name      string // Nazwa wpisu z konfiguracji
+	Instances int    // Liczba kopii z konfiguracji
+}
+
+// Funkcje dostępne w szablonach kopii, np. PORT={{add 8000 .Index}}
+var instanceTemplateFuncs = template.FuncMap{
+	"add": func(a, b int) int { return a + b },
+}
+
+// Rozwija wpis na kopie według instances; wpis bez instances zostaje bez zmian
+func ExpandInstances(pc ProcessConfig) ([]ProcessConfig, error) {
+	if pc.Instances < 0 {
+		return nil, fmt.Errorf("instances nie może być ujemne")
+	}
+	if pc.Instances == 0 {
+		return []ProcessConfig{pc}, nil
+	}
+
+	if err := checkInstanceFiles(pc); err != nil {
+		return nil, err
+	}
+	copies := make([]ProcessConfig, pc.Instances)
+	for i := range copies {
+		instance, err := ExpandInstance(pc, i)
+		if err != nil {
+			return nil, err
+		}
+		copies[i] = instance
+	}
+	return copies, nil
+}
+
+// Buduje kopię o numerze index: wypełnia szablony w nazwie, komendzie, plikach i env
+//
+// Nazwa bez szablonu dostaje przyrostek -<numer>. Kopia ma instances = 0.
+func ExpandInstance(pc ProcessConfig, index int) (ProcessConfig, error) {
+	data := InstanceData{Index: index, Name: pc.Name, Instances: pc.Instances}
+	var firstErr error
+	expand := func(field, value string) string {
+		if firstErr != nil || !strings.Contains(value, "{{") {
+			return value
+		}
+		result, err := executeInstanceTemplate(value, data)
+		if err != nil {
+			firstErr = fmt.Errorf("instances: %s: %v", field, err)
+		}
+		return result
+	}
+
+	instance := pc
+	instance.Instances = 0
+	if strings.Contains(pc.Name, "{{") {
+		instance.Name = expand("name", pc.Name)
+	} else {
+		instance.Name = fmt.Sprintf("%s-%d", pc.Name, index)
+	}
+	instance.Command = expand("command", pc.Command)
+	instance.LogFile = expand("log_file", pc.LogFile)
+	instance.WorkingDir = expand("working_dir", pc.WorkingDir)
+	instance.Stdout = expand("stdout", pc.Stdout)
+	instance.Stderr = expand("stderr", pc.Stderr)
+	if pc.Env != nil {
+		instance.Env = make([]string, len(pc.Env))
+		for i, kv := range pc.Env {
+			instance.Env[i] = expand(fmt.Sprintf("env[%d]", i), kv)
+		}
+	}
+	if pc.Watchdog != nil {
+		watchdog := *pc.Watchdog
+		watchdog.HeartbeatFile = expand("watchdog.heartbeat_file", watchdog.HeartbeatFile)
+		instance.Watchdog = &watchdog
+	}
+	return instance, firstErr
+}
+
+// Wypełnia pojedynczy szablon
+func executeInstanceTemplate(text string, data InstanceData) (string, error) {
+	tmpl, err := template.New("instance").Funcs(instanceTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Kopie nie mogą obserwować jednego pliku - cisza jednej zginęłaby w logach pozostałych
+func checkInstanceFiles(pc ProcessConfig) error {
+	first, err := ExpandInstance(pc, 0)
+	if err != nil {
+		return err
+	}
+	second, err := ExpandInstance(pc, 1)
+	if err != nil {
+		return err
+	}
+	if first.Name == second.Name {
+		return fmt.Errorf("instances: name musi zależeć od {{.Index}}")
+	}
+	if pc.LogFile != "" && first.LogFile == second.LogFile {
+		return fmt.Errorf("instances: log_file musi zależeć od {{.Index}}, np. /var/log/%s-{{.Index}}.log", pc.Name)
+	}
+	if pc.Watchdog != nil && pc.Watchdog.HeartbeatFile != "" && first.Watchdog.HeartbeatFile == second.Watchdog.HeartbeatFile {
+		return fmt.Errorf("instances: watchdog.heartbeat_file musi zależeć od {{.Index}}")
+	}
+	return nil
+}
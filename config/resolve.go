@@ -0,0 +1,204 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Wyrażenie ${NAZWA} lub ${NAZWA:-domyślna}
+var envReference = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// Podstawia zmienne środowiska w wartościach sparsowanego dokumentu YAML
+//
+// Podstawienie działa na zdekodowanych napisach, więc wartość zmiennej nigdy nie
+// zmienia struktury dokumentu (nowe linie, #, cudzysłowy zostają częścią napisu),
+// a komentarze i bloki | > nie wymagają osobnej obsługi. Klucze nie są rozwijane.
+func interpolateEnv(node interface{}, path string, lookup func(string) (string, bool)) (interface{}, error) {
+	switch v := node.(type) {
+	case map[interface{}]interface{}:
+		// Klucze po kolei, żeby przy kilku błędach zgłaszany był zawsze ten sam
+		keys := make([]interface{}, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, key := range keys {
+			value, err := interpolateEnv(v[key], joinPath(path, fmt.Sprint(key)), lookup)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = value
+		}
+	case []interface{}:
+		for i := range v {
+			value, err := interpolateEnv(v[i], fmt.Sprintf("%s[%d]", path, i), lookup)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = value
+		}
+	case string:
+		value, err := expandEnv(v, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		return value, nil
+	}
+	return node, nil
+}
+
+// Ścieżka pola w komunikatach błędów, np. processes[0].command
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// Rozwija zmienne w jednym napisie
+//
+// ${NAZWA:-domyślna} daje domyślną wartość, gdy zmienna jest nieustawiona lub pusta;
+// nieustawiona zmienna bez domyślnej wartości to błąd. $${ zostawia dosłowne ${.
+// Wynik w kanonicznej postaci liczby lub true/false staje się liczbą lub wartością
+// logiczną, żeby działało np. timeout: ${TIMEOUT:-60}.
+func expandEnv(s string, lookup func(string) (string, bool)) (interface{}, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	substituted := false
+	for i := 0; i < len(s); {
+		rest := s[i:]
+		if strings.HasPrefix(rest, "$${") {
+			b.WriteString("${")
+			i += 3
+			continue
+		}
+		if !strings.HasPrefix(rest, "${") {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+
+		m := envReference.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("nieprawidłowe wyrażenie %s (dozwolone ${NAZWA} i ${NAZWA:-domyślna}, dosłowne ${ to $${)", rest)
+		}
+		value, ok := lookup(m[1])
+		switch {
+		case m[2] != "" && value == "":
+			value = m[3]
+		case !ok:
+			return nil, fmt.Errorf("zmienna %s nie jest ustawiona (domyślną wartość podaje ${%s:-wartość}, zmienną dla powłoki procesu zapisz jako $${%s})",
+				m[1], m[1], m[1])
+		}
+		b.WriteString(value)
+		substituted = true
+		i += len(m[0])
+	}
+
+	result := b.String()
+	if !substituted {
+		return result, nil
+	}
+	if n, err := strconv.Atoi(result); err == nil && strconv.Itoa(n) == result {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(result, 64); err == nil && strconv.FormatFloat(f, 'f', -1, 64) == result {
+		return f, nil
+	}
+	if result == "true" || result == "false" {
+		return result == "true", nil
+	}
+	return result, nil
+}
+
+// Zapisuje wartości tak, żeby ponowne wczytanie nie rozwinęło ich jako zmiennych
+func escapeEnv(s string) string {
+	return strings.ReplaceAll(s, "${", "$${")
+}
+
+// Uzupełnia wpis wartościami z sekcji defaults
+//
+// Pole ustawione we wpisie wygrywa; env jest łączone (ta sama nazwa we wpisie
+// nadpisuje wartość z defaults).
+func applyDefaults(pc *ProcessConfig, defaults ProcessConfig) {
+	if pc.Command == "" {
+		pc.Command = defaults.Command
+	}
+	if pc.LogFile == "" {
+		pc.LogFile = defaults.LogFile
+	}
+	if pc.Timeout == 0 {
+		pc.Timeout = defaults.Timeout
+	}
+	if pc.Interval == 0 {
+		pc.Interval = defaults.Interval
+	}
+	if pc.Instances == 0 {
+		pc.Instances = defaults.Instances
+	}
+	if pc.WorkingDir == "" {
+		pc.WorkingDir = defaults.WorkingDir
+	}
+	if len(defaults.Env) > 0 {
+		pc.Env = append(append([]string{}, defaults.Env...), pc.Env...)
+	}
+	if pc.User == "" {
+		pc.User = defaults.User
+	}
+	if pc.Group == "" {
+		pc.Group = defaults.Group
+	}
+	if pc.Stdout == "" {
+		pc.Stdout = defaults.Stdout
+	}
+	if pc.Stderr == "" {
+		pc.Stderr = defaults.Stderr
+	}
+	if pc.RestartSchedule == "" {
+		pc.RestartSchedule = defaults.RestartSchedule
+	}
+	if pc.MaintenanceWindows == nil {
+		pc.MaintenanceWindows = defaults.MaintenanceWindows
+	}
+	if pc.AdaptiveTimeout == nil && defaults.AdaptiveTimeout != nil {
+		adaptive := *defaults.AdaptiveTimeout
+		pc.AdaptiveTimeout = &adaptive
+	}
+	if pc.Watchdog == nil && defaults.Watchdog != nil {
+		watchdog := *defaults.Watchdog
+		pc.Watchdog = &watchdog
+	}
+	if pc.Notifications == nil && defaults.Notifications != nil {
+		notifications := *defaults.Notifications
+		pc.Notifications = &notifications
+	}
+}
+
+// Zapisuje rozwiązaną konfigurację: zmienne podstawione, defaults wpisane do procesów
+func WriteConfig(w io.Writer, cfg *Config) error {
+	global := struct {
+		StateFile     string             `yaml:"state_file,omitempty"`
+		ControlSocket string             `yaml:"control_socket,omitempty"`
+		Notifications NotificationConfig `yaml:"notifications,omitempty"`
+	}{cfg.StateFile, cfg.ControlSocket, cfg.Notifications}
+
+	data, err := yaml.Marshal(global)
+	if err != nil {
+		return err
+	}
+	if text := string(data); text != "{}\n" {
+		if _, err := fmt.Fprintf(w, "%s\n", escapeEnv(text)); err != nil {
+			return err
+		}
+	}
+	return WriteProcesses(w, cfg.Processes)
+}
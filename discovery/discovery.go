@@ -0,0 +1,487 @@
+// Pakiet discovery wyszukuje w systemie procesy, które warto pilnować monitorem,
+// ocenia czy można je bezpiecznie restartować i generuje dla nich konfigurację.
+package discovery
+
+import (
+    "fmt"
+    "io"
+    "monitor_mutex/config"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Kandydat do monitorowania
+type ProcessCandidate struct {
+    Name        string
+    ConfigName  string        // Unikalna nazwa wpisu konfiguracji (skrypt, moduł, port)
+    PID         string
+    Command     string
+    LogFile     string
+    Port        string
+    User        string
+    CPUUsage    string
+    MemoryUsage string
+    Age         time.Duration // Jak długo proces działa
+    Sources     []string      // Dlaczego znaleziony: log, port, long-running
+    LogTargets  []LogTarget   // Gdzie proces zapisuje logi, od najbardziej prawdopodobnego
+    ManagedBy   Management    // Jednostka systemd, kontener lub nadzorca, który już pilnuje procesu
+    Cgroups     []string      // Ścieżki z /proc/<pid>/cgroup (do reguł cgroup)
+    Launch      LaunchSpec    // Katalog, środowisko, użytkownik i przekierowania oryginału
+    Children    []ChildProcess // Procesy potomne zwinięte do tego korzenia usługi
+    Score       Score         // Ocena z RankCandidates: czy warto pilnować i dlaczego
+    Cadence     *LogCadence   // Rytm zapisów do logu z ObserveLogs (nil = bez obserwacji)
+}
+
+// Metody wykrywania zapisywane w ProcessCandidate.Sources
+const (
+    sourceLog         = "log"
+    sourcePort        = "port"
+    sourceLongRunning = "long-running"
+)
+
+// Wykrywa kandydatów do monitorowania (jeden przebieg po /proc)
+func Discover() []ProcessCandidate {
+    fmt.Println("🔍 Skanowanie systemu w poszukiwaniu procesów...")
+    
+    // Jeden odczyt /proc wspólny dla wszystkich metod wykrywania
+    procs, err := scanProcs()
+    if err != nil {
+        fmt.Printf("   ❌ Błąd skanowania /proc: %v\n", err)
+        return nil
+    }
+    
+    var candidates []ProcessCandidate
+    
+    // 1. Procesy z otwartymi plikami logów
+    logCandidates := findProcessesWithLogs(procs)
+    fmt.Printf("   Znaleziono %d procesów z plikami logów\n", len(logCandidates))
+    candidates = append(candidates, logCandidates...)
+    
+    // 2. Procesy nasłuchujące na portach
+    portCandidates := findListeningProcesses(procs)
+    fmt.Printf("   Znaleziono %d procesów nasłuchujących\n", len(portCandidates))
+    candidates = append(candidates, portCandidates...)
+    
+    // 3. Długo działające procesy
+    longCandidates := findLongRunningProcesses(procs)
+    fmt.Printf("   Znaleziono %d długo działających procesów\n", len(longCandidates))
+    candidates = append(candidates, longCandidates...)
+    
+    // Samo discovery pisze na stdout i bywa liczone jako kandydat - pomiń je
+    self := strconv.Itoa(os.Getpid())
+    others := candidates[:0]
+    for _, candidate := range candidates {
+        if candidate.PID != self {
+            others = append(others, candidate)
+        }
+    }
+    candidates = others
+    
+    // Zwiń skrypty i ich dzieci (bash, sleep, python) do korzenia usługi
+    candidates = collapseTree(candidates, procs)
+    
+    // Połącz wpisy tego samego procesu znalezione różnymi metodami
+    candidates = removeDuplicates(candidates)
+    
+    // Nazwy wpisów konfiguracji: app zamiast bash, python-8000 i python-8001 zamiast dwóch python
+    assignConfigNames(candidates)
+    
+    // Kto już zarządza procesami (systemd, kontener, supervisord...)
+    annotateManagement(candidates, procs)
+    
+    // Jak odtworzyć uruchomienie (cwd, środowisko, użytkownik, przekierowania)
+    annotateLaunch(candidates, procs)
+    
+    fmt.Printf("   Łącznie: %d unikalnych kandydatów\n\n", len(candidates))
+    return candidates
+}
+
+// Znajdź procesy zapisujące logi (stdout/stderr, pliki otwarte do zapisu, journald/syslog)
+func findProcessesWithLogs(procs []*procInfo) []ProcessCandidate {
+    var candidates []ProcessCandidate
+    
+    peers := unixSocketPeers()
+    now := time.Now()
+    
+    for _, p := range procs {
+        if len(p.Cmdline) == 0 {
+            continue // Wątki jądra nie mają komendy
+        }
+        
+        targets := processLogTargets(p, peers, now)
+        if len(targets) == 0 {
+            continue
+        }
+        
+        candidates = append(candidates, ProcessCandidate{
+            Name:       p.Comm,
+            PID:        strconv.Itoa(p.PID),
+            User:       p.User,
+            LogFile:    bestLogFile(targets),
+            LogTargets: targets,
+            Command:    p.command(),
+            Age:        p.Elapsed,
+            Sources:    []string{sourceLog},
+        })
+    }
+    
+    return candidates
+}
+
+// Znajdź procesy nasłuchujące na portach (gniazda z /proc/net/tcp{,6} powiązane przez i-węzły)
+func findListeningProcesses(procs []*procInfo) []ProcessCandidate {
+    sockets := listeningSockets()
+    if len(sockets) == 0 {
+        return nil
+    }
+    
+    var candidates []ProcessCandidate
+    
+    for _, p := range procs {
+        seen := make(map[string]bool) // Ten sam port na IPv4 i IPv6
+        for _, target := range procFDTargets(p.PID) {
+            inode, ok := socketInode(target)
+            if !ok {
+                continue
+            }
+            port, listening := sockets[inode]
+            if !listening || seen[port] {
+                continue
+            }
+            seen[port] = true
+            
+            candidates = append(candidates, ProcessCandidate{
+                Name:    p.Comm,
+                PID:     strconv.Itoa(p.PID),
+                User:    p.User,
+                Port:    port,
+                Command: p.command(),
+                Age:     p.Elapsed,
+                Sources: []string{sourcePort},
+            })
+        }
+    }
+    
+    return candidates
+}
+
+// Znajdź długo działające procesy (starsze niż 1 godzina)
+// Kolejność i wybór najciekawszych ustala później RankCandidates
+func findLongRunningProcesses(procs []*procInfo) []ProcessCandidate {
+    var candidates []ProcessCandidate
+    
+    for _, p := range procs {
+        if len(p.Cmdline) > 0 && isLongRunning(p.Elapsed) {
+            candidates = append(candidates, ProcessCandidate{
+                Name:        extractProcessName(p.Cmdline),
+                PID:         strconv.Itoa(p.PID),
+                User:        p.User,
+                CPUUsage:    fmt.Sprintf("%.1f", p.CPUPercent),
+                MemoryUsage: fmt.Sprintf("%.1f", p.MemPercent),
+                Command:     p.command(),
+                Age:         p.Elapsed,
+                Sources:     []string{sourceLongRunning},
+            })
+        }
+    }
+    
+    return candidates
+}
+
+// Sprawdź czy proces działa długo (dłużej niż godzinę)
+func isLongRunning(elapsed time.Duration) bool {
+    return elapsed >= time.Hour
+}
+
+// Wyciągnij nazwę procesu z komendy
+func extractProcessName(cmdFields []string) string {
+    if len(cmdFields) == 0 {
+        return "unknown"
+    }
+    
+    cmd := cmdFields[0]
+    
+    // Usuń ścieżkę
+    if strings.Contains(cmd, "/") {
+        parts := strings.Split(cmd, "/")
+        cmd = parts[len(parts)-1]
+    }
+    
+    // Usuń argumenty
+    if strings.Contains(cmd, " ") {
+        cmd = strings.Split(cmd, " ")[0]
+    }
+    
+    return cmd
+}
+
+// Łączy kandydatów tego samego procesu (PID) znalezionych różnymi metodami
+func removeDuplicates(candidates []ProcessCandidate) []ProcessCandidate {
+    seen := make(map[string]int) // Klucz -> indeks w unique
+    var unique []ProcessCandidate
+    
+    for _, candidate := range candidates {
+        // Ten sam proces ma jeden PID niezależnie od metody; bez PID zostaje nazwa i komenda
+        key := "pid:" + candidate.PID
+        if candidate.PID == "" {
+            key = candidate.Name + ":" + candidate.Command
+        }
+        
+        if i, ok := seen[key]; ok {
+            // Zapamiętaj wszystkie metody, którymi znaleziono proces, oraz port, log, CPU
+            mergeCandidate(&unique[i], candidate)
+            continue
+        }
+        seen[key] = len(unique)
+        unique = append(unique, candidate)
+    }
+    
+    // Podstawowa walidacja dopiero po połączeniu - wpis z portem mógł nie mieć nazwy
+    valid := unique[:0]
+    for _, candidate := range unique {
+        if candidate.Name == "" || candidate.Name == "unknown" {
+            candidate.Name = extractProcessName(strings.Fields(candidate.Command))
+        }
+        if candidate.Name == "unknown" || 
+           candidate.Command == "" ||
+           candidate.Command == candidate.LogFile {
+            continue
+        }
+        valid = append(valid, candidate)
+    }
+    
+    return valid
+}
+
+// Sprawdź czy lista zawiera napis
+func containsString(list []string, s string) bool {
+    for _, item := range list {
+        if item == s {
+            return true
+        }
+    }
+    return false
+}
+
+// Zasady oceny kandydatów
+type Policy struct {
+    IncludeManaged bool   // Nie odrzucaj procesów zarządzanych przez systemd, kontener lub innego nadzorcę
+    Rules          *Rules // Filtry i profile (nil = reguły wbudowane)
+}
+
+// Decyzja podjęta dla kandydata przez SuggestConfiguration
+type CandidateDecision struct {
+    Accepted bool
+    Check    string // Reguła, która rozstrzygnęła: command, managed albo check filtra (system, safety...)
+    Reason   string
+    Profile  string // Profil parametrów (np. "serwer web"), tylko dla zaakceptowanych
+    Config   config.ProcessConfig
+}
+
+// Ocenia kandydata: odrzuca niebezpieczne procesy i dobiera parametry dla pozostałych
+func EvaluateCandidate(candidate ProcessCandidate, policy Policy) CandidateDecision {
+    // 1. Podstawowa walidacja
+    if candidate.Command == "" || candidate.Command == candidate.LogFile {
+        return CandidateDecision{Check: "command", Reason: "brak poprawnej komendy"}
+    }
+    
+    // 1a. Nie przejmuj procesów, które ktoś już nadzoruje (podwójny restart)
+    if candidate.ManagedBy.Managed() && !policy.IncludeManaged {
+        return CandidateDecision{Check: "managed", Reason: "zarządzany przez " + candidate.ManagedBy.String()}
+    }
+    
+    // 2. Filtry z reguł (procesy systemowe, bezpieczeństwo restartu)
+    rules := policy.Rules
+    if rules == nil {
+        rules = DefaultRules()
+    }
+    in := candidateInput(candidate)
+    accepted, check, reason := rules.filter(in)
+    if !accepted {
+        return CandidateDecision{Check: check, Reason: reason}
+    }
+    
+    // 3. Tworzenie konfiguracji
+    name := candidate.ConfigName
+    if name == "" {
+        name = serviceName(candidate)
+    }
+    pc := config.ProcessConfig{
+        Name:       name,
+        Command:    candidate.Command,
+        WorkingDir: candidate.Launch.WorkingDir,
+        Env:        candidate.Launch.Env,
+        User:       candidate.Launch.User,
+        Group:      candidate.Launch.Group,
+        Stdout:     candidate.Launch.Stdout,
+        Stderr:     candidate.Launch.Stderr,
+    }
+    
+    // 4. Ustaw plik logów
+    if candidate.LogFile != "" {
+        pc.LogFile = candidate.LogFile
+    } else {
+        pc.LogFile = suggestedLogFile(candidate.User, pc.Name)
+    }
+    
+    // 5. Dostosuj parametry do typu procesu (profil dopasowany do docelowego pliku logów)
+    in.logFile = pc.LogFile
+    profile := rules.profile(in)
+    profileName := profile.Profile
+    profile.apply(&pc)
+    
+    // 5a. Zaobserwowany rytm logu (--observe) jest lepszy niż zgadywanie po nazwie
+    if c := candidate.Cadence; c != nil && c.Timeout > 0 {
+        pc.Timeout = c.Timeout
+        pc.Interval = c.Interval
+        profileName = "z obserwacji logu"
+    }
+    
+    // 6. Walidacja końcowa
+    if pc.Timeout < pc.Interval {
+        pc.Timeout = pc.Interval * 3 // Minimum 3 interwały
+    }
+    
+    return CandidateDecision{
+        Accepted: true,
+        Check:    check,
+        Reason:   reason,
+        Profile:  profileName,
+        Config:   pc,
+    }
+}
+
+// Sugeruje bezpieczną lokalizację logów dla procesu bez wykrytego pliku
+func suggestedLogFile(user, name string) string {
+    if user != "" && user != "root" {
+        return fmt.Sprintf("/tmp/%s_%s.log", user, strings.ToLower(name))
+    }
+    return fmt.Sprintf("/tmp/%s.log", strings.ToLower(name))
+}
+
+// Sugeruj konfigurację dla wybranych procesów
+func SuggestConfiguration(candidates []ProcessCandidate, policy Policy) []config.ProcessConfig {
+    var configs []config.ProcessConfig
+    names := make(map[string]bool) // Nazwy muszą być unikalne także po wyborze procesu potomnego
+    
+    fmt.Println("\n🔧 Generowanie konfiguracji...")
+    fmt.Println("==============================")
+    
+    for _, candidate := range candidates {
+        decision := EvaluateCandidate(candidate, policy)
+        if !decision.Accepted {
+            switch decision.Check {
+            case "command":
+                fmt.Printf("⚠️  Pominięto %s - brak poprawnej komendy\n", candidate.Name)
+            case "managed":
+                fmt.Printf("🔒 Pominięto %s - %s (użyj --include-managed, aby go dodać)\n", 
+                          candidate.Name, decision.Reason)
+            case "system":
+                fmt.Printf("🚫 Pominięto proces systemowy: %s (%s) - %s\n", 
+                          candidate.Name, candidate.Command, decision.Reason)
+            default:
+                fmt.Printf("⚠️  Pominięto niebezpieczny proces: %s - %s\n", 
+                          candidate.Name, decision.Reason)
+            }
+            continue
+        }
+        
+        pc := decision.Config
+        pc.Name = uniqueName(pc.Name, names)
+        configs = append(configs, pc)
+        
+        // Pokaż informacje o dodanym procesie
+        status := "✅"
+        if candidate.User == "root" {
+            status = "⚠️ "
+        }
+        
+        fmt.Printf("%s %s -> %s (timeout: %ds, interval: %ds)\n", 
+                   status, pc.Name, pc.LogFile, pc.Timeout, pc.Interval)
+        
+        if candidate.Port != "" {
+            fmt.Printf("    🌐 Port: %s\n", candidate.Port)
+        }
+        if candidate.User != "" {
+            fmt.Printf("    👤 Użytkownik: %s\n", candidate.User)
+        }
+        if pc.WorkingDir != "" {
+            fmt.Printf("    📁 Katalog: %s\n", pc.WorkingDir)
+        }
+        if len(candidate.Launch.SecretEnv) > 0 {
+            fmt.Printf("    🔑 Pominięto zmienne z sekretami: %s - uzupełnij env ręcznie\n", 
+                       strings.Join(candidate.Launch.SecretEnv, ", "))
+        }
+        if candidate.LogFile == "" && len(candidate.LogTargets) > 0 {
+            fmt.Printf("    📝 Logi trafiają do %s - monitor potrzebuje pliku, wpisano sugerowany\n", 
+                       candidate.LogTargets[0].Kind)
+        }
+        if candidate.ManagedBy.Managed() {
+            fmt.Printf("    🔒 Uwaga: zarządzany także przez %s\n", candidate.ManagedBy)
+        }
+        if c := candidate.Cadence; c != nil {
+            switch {
+            case c.Timeout > 0:
+                fmt.Printf("    ⏱️  Z obserwacji: %s\n", c)
+            case c.Bursty:
+                fmt.Printf("    ⚠️  Log pisany seriami - nadzór po ciszy w logu może restartować zdrowy proces; timeout z profilu %s\n", 
+                           decision.Profile)
+            default:
+                fmt.Printf("    ⏱️  Obserwacja bez wniosku: %s - timeout z profilu %s\n", c.Note, decision.Profile)
+            }
+        }
+    }
+    
+    // Podsumowanie
+    fmt.Printf("\n📊 Podsumowanie:\n")
+    fmt.Printf("   Kandydatów: %d\n", len(candidates))
+    fmt.Printf("   Zaakceptowanych: %d\n", len(configs))
+    fmt.Printf("   Odrzuconych: %d\n", len(candidates)-len(configs))
+    
+    if len(configs) == 0 {
+        fmt.Println("\n⚠️  Brak procesów spełniających kryteria do monitorowania")
+        fmt.Println("💡 Sugestie:")
+        fmt.Println("   - Uruchom własne aplikacje (python, node, java)")
+        fmt.Println("   - Sprawdź procesy użytkownika (nie root)")
+        fmt.Println("   - Utwórz testowe procesy w /tmp/")
+        fmt.Println("\n   Przykład testowego procesu:")
+        fmt.Println("   nohup bash -c 'while true; do echo $(date) Test app; sleep 10; done > /tmp/test.log' &")
+    } else {
+        fmt.Println("\n✅ Konfiguracja gotowa do użycia!")
+    }
+    
+    return configs
+}
+
+// Zapisz konfigurację do pliku YAML
+func SaveConfiguration(configs []config.ProcessConfig, filename string) error {
+    file, err := os.Create(filename)
+    if err != nil {
+        return fmt.Errorf("nie można utworzyć pliku: %v", err)
+    }
+    defer file.Close()
+
+    if err := WriteConfiguration(file, configs, filename); err != nil {
+        return err
+    }
+    return file.Close()
+}
+
+// Wypisz konfigurację YAML (do pliku albo na stdout przy --dry-run)
+func WriteConfiguration(file io.Writer, configs []config.ProcessConfig, filename string) error {
+    // Nagłówek pliku
+    fmt.Fprintf(file, "# Automatycznie wygenerowana konfiguracja monitora\n")
+    fmt.Fprintf(file, "# Data: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+    fmt.Fprintf(file, "# Użycie: monitor_mutex --config %s\n\n", filename)
+
+    // Wpisy kodowane tym samym formatem, który czyta monitor_mutex
+    if err := config.WriteProcesses(file, configs); err != nil {
+        return fmt.Errorf("błąd kodowania YAML: %v", err)
+    }
+
+    fmt.Fprintf(file, "# Uruchom monitor poleceniem:\n")
+    _, err := fmt.Fprintf(file, "# ./monitor_mutex --config %s\n", filename)
+    return err
+}
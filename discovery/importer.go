@@ -0,0 +1,204 @@
+package discovery
+
+import (
+	"fmt"
+	"monitor_mutex/config"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Wpis zaimportowany z definicji innego nadzorcy
+type ImportedProcess struct {
+	Source   string // Skąd pochodzi wpis (plik i sekcja)
+	Config   config.ProcessConfig
+	Profile  string   // Profil reguł, z którego wzięto timeout i interval
+	Unmapped []string // Opcje bez odpowiednika w monitor_mutex
+}
+
+// Obsługiwane formaty importu
+const (
+	FormatSupervisord = "supervisord"
+	FormatSystemd     = "systemd"
+	FormatProcfile    = "procfile"
+)
+
+var (
+	supervisordProgramPattern = regexp.MustCompile(`(?m)^\s*\[program:`)
+	systemdServicePattern     = regexp.MustCompile(`(?m)^\s*\[Service\]`)
+)
+
+// Rozpoznaje format po nazwie i treści pliku
+func DetectImportFormat(filename string, data []byte) (string, error) {
+	base := filepath.Base(filename)
+	switch {
+	case strings.HasSuffix(base, ".service") || systemdServicePattern.Match(data):
+		return FormatSystemd, nil
+	case strings.HasPrefix(base, "Procfile"):
+		return FormatProcfile, nil
+	case supervisordProgramPattern.Match(data):
+		return FormatSupervisord, nil
+	}
+	return "", fmt.Errorf("nie rozpoznano formatu %s (podaj go wprost: %s, %s lub %s)",
+		filename, FormatSupervisord, FormatSystemd, FormatProcfile)
+}
+
+// Importuje definicje procesów z pliku (format "" = rozpoznaj automatycznie)
+//
+// Timeout i interval pochodzą z profili reguł, tak jak dla wykrytych procesów. Proces
+// bez pliku wyjścia dostaje stdout i stderr w pliku, który monitor będzie obserwował.
+func ImportFile(filename, format string, rules *Rules) ([]ImportedProcess, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("nie można odczytać %s: %v", filename, err)
+	}
+	if format == "" {
+		if format, err = DetectImportFormat(filename, data); err != nil {
+			return nil, err
+		}
+	}
+
+	var imported []ImportedProcess
+	switch format {
+	case FormatSupervisord:
+		imported, err = parseSupervisord(filename, data, map[string]bool{})
+	case FormatSystemd:
+		imported, err = parseUnitFile(filename, data)
+	case FormatProcfile:
+		imported, err = parseProcfile(filename, data)
+	default:
+		return nil, fmt.Errorf("nieznany format importu %q (dostępne: %s, %s, %s)",
+			format, FormatSupervisord, FormatSystemd, FormatProcfile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if rules == nil {
+		rules = DefaultRules()
+	}
+	for i := range imported {
+		completeImported(&imported[i], rules)
+	}
+	return imported, nil
+}
+
+// Uzupełnia to, czego inni nadzorcy nie znają: plik logów do obserwacji, timeout i interval
+func completeImported(p *ImportedProcess, rules *Rules) {
+	pc := &p.Config
+	pc.Name = sanitizeName(pc.Name)
+
+	if pc.LogFile == "" {
+		pc.LogFile = pc.Stdout
+	}
+	if pc.LogFile == "" {
+		pc.LogFile = suggestedLogFile(pc.User, pc.Name)
+		pc.Stdout = pc.LogFile
+		if pc.Stderr == "" {
+			pc.Stderr = pc.LogFile
+		}
+	}
+
+	in := matchInput{name: pc.Name, command: pc.Command, user: pc.User, logFile: pc.LogFile}
+	if fields := strings.Fields(pc.Command); len(fields) > 0 {
+		in.path = fields[0]
+	}
+	profile := rules.profile(in)
+	p.Profile = profile.Profile
+	profile.apply(pc)
+
+	// Monitor wymaga kilku sprawdzeń w czasie watchdoga
+	if w := pc.Watchdog; w != nil && w.Timeout > 0 && w.Timeout < 2*pc.Interval {
+		pc.Interval = w.Timeout / 2
+		if pc.Interval < 1 {
+			pc.Interval = 1
+			w.Timeout = 2
+		}
+	}
+}
+
+// Wpisy konfiguracji z unikalnymi nazwami (te same nazwy z różnych plików dostają numer)
+func ImportedConfigs(imported []ImportedProcess) []config.ProcessConfig {
+	used := make(map[string]bool)
+	configs := make([]config.ProcessConfig, len(imported))
+	for i, p := range imported {
+		pc := p.Config
+		pc.Name = uniqueName(p.Config.Name, used)
+
+		// Zaproponowany plik logów idzie za nazwą, żeby dwa wpisy nie pisały do jednego pliku
+		if suggested := suggestedLogFile(pc.User, p.Config.Name); pc.Name != p.Config.Name && pc.LogFile == suggested {
+			renamed := suggestedLogFile(pc.User, pc.Name)
+			pc.LogFile = renamed
+			if pc.Stdout == suggested {
+				pc.Stdout = renamed
+			}
+			if pc.Stderr == suggested {
+				pc.Stderr = renamed
+			}
+		}
+		configs[i] = pc
+	}
+	return configs
+}
+
+// Dzieli listę przypisań KLUCZ=wartość z cudzysłowami (Environment= systemd, PATH="a b")
+func splitQuoted(s string, sep func(rune) bool) []string {
+	var fields []string
+	var current strings.Builder
+	var quote rune
+	inField := false
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inField = true
+		case sep(r):
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+		default:
+			current.WriteRune(r)
+			inField = true
+		}
+	}
+	if inField {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
+
+// Skleja argumenty w komendę dla sh -c, cytując tylko to, co trzeba
+//
+// Argumenty z $ trafiają do podwójnych cudzysłowów, żeby powłoka rozwinęła zmienne
+// tak jak systemd; "$$" (dosłowny $ w systemd) staje się \$.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		switch {
+		case arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\`$;&|<>()*?[]#~!{}"):
+			quoted[i] = arg
+		case strings.Contains(arg, "$"):
+			arg = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", "$$", `\$`).Replace(arg)
+			quoted[i] = `"` + arg + `"`
+		default:
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
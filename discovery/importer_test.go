@@ -0,0 +1,189 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"monitor_mutex/config"
+)
+
+// Zapisuje plik testowy w katalogu dir
+func writeImportFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// Czy któraś z uwag zaczyna się od podanego klucza
+func hasNote(notes []string, prefix string) bool {
+	for _, note := range notes {
+		if strings.HasPrefix(note, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sekcje [program:x] z dołączonymi plikami, zmiennymi i przekierowaniem stderr
+func TestImportSupervisord(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("WEB_PORT", "8000")
+	writeImportFile(t, dir, "conf.d/worker.conf", `[program:worker]
+command=python3 worker.py --queue %(program_name)s
+  --verbose
+numprocs=4
+`)
+	main := writeImportFile(t, dir, "supervisord.conf", `[supervisord]
+logfile=/var/log/supervisord.log
+
+[include]
+files = conf.d/*.conf
+
+[program:web]
+command=/opt/web/bin/gunicorn -b 0.0.0.0:%(ENV_WEB_PORT)s app:app ; port z env
+directory=/opt/web
+user=www-data
+environment=APP_ENV="prod",GREETING='a,b'
+stdout_logfile=/var/log/web.log
+redirect_stderr=True
+autorestart=true
+stopsignal=TERM
+stopwaitsecs=30
+`)
+
+	imported, err := ImportFile(main, "", nil)
+	if err != nil {
+		t.Fatalf("ImportFile: %v", err)
+	}
+	if len(imported) != 2 {
+		t.Fatalf("zaimportowano %d programów, oczekiwano 2", len(imported))
+	}
+
+	web := imported[0].Config
+	want := config.ProcessConfig{
+		Name:       "web",
+		Command:    "/opt/web/bin/gunicorn -b 0.0.0.0:8000 app:app",
+		LogFile:    "/var/log/web.log",
+		WorkingDir: "/opt/web",
+		Env:        []string{"APP_ENV=prod", "GREETING=a,b"},
+		User:       "www-data",
+		Stdout:     "/var/log/web.log",
+		Stderr:     "/var/log/web.log",
+		Timeout:    web.Timeout,
+		Interval:   web.Interval,
+	}
+	if !reflect.DeepEqual(web, want) {
+		t.Errorf("web:\n%+v\noczekiwano\n%+v", web, want)
+	}
+	if notes := imported[0].Unmapped; len(notes) != 1 || !hasNote(notes, "stopwaitsecs=30") {
+		t.Errorf("web: nieprzeniesione %q, oczekiwano tylko stopwaitsecs", notes)
+	}
+
+	worker := imported[1]
+	if worker.Config.Command != "python3 worker.py --queue worker --verbose" {
+		t.Errorf("worker: komenda %q", worker.Config.Command)
+	}
+	if worker.Config.Stdout == "" || worker.Config.LogFile != worker.Config.Stdout || worker.Config.Stderr != worker.Config.Stdout {
+		t.Errorf("worker bez pliku logów powinien dostać stdout i stderr w log_file: %+v", worker.Config)
+	}
+	if !hasNote(worker.Unmapped, "numprocs=4") {
+		t.Errorf("worker: brak uwagi o numprocs w %q", worker.Unmapped)
+	}
+}
+
+// Usługa systemd: cytowanie ExecStart, specyfikatory, watchdog i czasy
+func TestImportSystemd(t *testing.T) {
+	unit := writeImportFile(t, t.TempDir(), "api.service", `[Unit]
+Description=API
+After=network.target
+
+[Service]
+Type=notify
+ExecStart=/opt/api/server --config /etc/api/%N.yaml \
+    --greeting "hello world" --price '$$5'
+Environment="LANG=pl_PL.UTF-8" TZ=UTC
+WorkingDirectory=-/opt/api
+User=api
+WatchdogSec=1min 30s
+TimeoutStartSec=2min
+Restart=always
+StandardOutput=append:/var/log/api.log
+StandardError=inherit
+KillMode=process
+
+[Install]
+WantedBy=multi-user.target
+`)
+
+	imported, err := ImportFile(unit, "", nil)
+	if err != nil {
+		t.Fatalf("ImportFile: %v", err)
+	}
+	p := imported[0]
+	pc := p.Config
+
+	if want := `/opt/api/server --config /etc/api/api.yaml --greeting 'hello world' --price "\$5"`; pc.Command != want {
+		t.Errorf("komenda %q, oczekiwano %q", pc.Command, want)
+	}
+	if pc.Name != "api" || pc.User != "api" || pc.WorkingDir != "/opt/api" || pc.Stderr != "/var/log/api.log" || pc.LogFile != "/var/log/api.log" {
+		t.Errorf("wpis: %+v", pc)
+	}
+	if !reflect.DeepEqual(pc.Env, []string{"LANG=pl_PL.UTF-8", "TZ=UTC"}) {
+		t.Errorf("env %q", pc.Env)
+	}
+	if w := pc.Watchdog; w == nil || !w.Notify || w.Timeout != 90 || w.ReadyTimeout != 120 {
+		t.Errorf("watchdog %+v", pc.Watchdog)
+	}
+	if !hasNote(p.Unmapped, "[Unit] After=") || !hasNote(p.Unmapped, "KillMode=process") || len(p.Unmapped) != 2 {
+		t.Errorf("nieprzeniesione %q", p.Unmapped)
+	}
+
+	for value, want := range map[string]int{"30": 30, "30s": 30, "2min": 120, "1h 30min": 5400, "500ms": 1, "infinity": 0} {
+		if got, ok := parseTimespan(value); !ok || got != want {
+			t.Errorf("parseTimespan(%q) = %d, %v, oczekiwano %d", value, got, ok, want)
+		}
+	}
+	if _, ok := parseTimespan("5 lat"); ok {
+		t.Error("parseTimespan przyjął nieznaną jednostkę")
+	}
+}
+
+// Procfile: katalog, zmienne z .env, PORT jak w foreman i unikalne pliki logów
+func TestImportProcfile(t *testing.T) {
+	dir := t.TempDir()
+	writeImportFile(t, dir, ".env", "RAILS_ENV=production\nexport SECRET=\"x y\"\n")
+	procfile := writeImportFile(t, dir, "Procfile", "# aplikacja\nweb: bundle exec puma -p $PORT\nworker: bundle exec sidekiq\n")
+
+	imported, err := ImportFile(procfile, "", nil)
+	if err != nil {
+		t.Fatalf("ImportFile: %v", err)
+	}
+	if len(imported) != 2 {
+		t.Fatalf("zaimportowano %d procesów, oczekiwano 2", len(imported))
+	}
+	if want := []string{"RAILS_ENV=production", "SECRET=x y", "PORT=5000"}; !reflect.DeepEqual(imported[0].Config.Env, want) {
+		t.Errorf("web env %q, oczekiwano %q", imported[0].Config.Env, want)
+	}
+	if imported[1].Config.WorkingDir != dir || len(imported[1].Config.Env) != 2 {
+		t.Errorf("worker: %+v", imported[1].Config)
+	}
+
+	// Ta sama nazwa z drugiego pliku dostaje numer i własny plik logów
+	configs := ImportedConfigs(append(imported, imported[0]))
+	if configs[2].Name != "web-2" || configs[2].LogFile == configs[0].LogFile || configs[2].Stdout != configs[2].LogFile {
+		t.Errorf("powtórzony wpis: %+v", configs[2])
+	}
+
+	if _, err := ImportFile(writeImportFile(t, dir, "Procfile.bad", "web bundle exec puma\n"), "", nil); err == nil {
+		t.Error("oczekiwano błędu dla linii bez dwukropka")
+	}
+}
@@ -0,0 +1,146 @@
+package discovery
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Ustawienia potrzebne, żeby restartowany proces zachowywał się jak oryginał
+type LaunchSpec struct {
+	WorkingDir string   // Z /proc/<pid>/cwd
+	Env        []string // Istotne zmienne środowiska KLUCZ=wartość
+	SecretEnv  []string // Nazwy zmiennych pominiętych, bo wyglądają na sekrety
+	User       string   // Użytkownik, gdy inny niż uruchamiający discovery
+	Group      string   // Grupa, gdy inna niż główna grupa użytkownika
+	Stdout     string   // Plik podpięty pod fd 1
+	Stderr     string   // Plik podpięty pod fd 2
+}
+
+// Zmienne ustawiane przez powłokę, sesję lub systemd - nie opisują aplikacji
+var sessionEnv = []string{
+	"_", "PWD", "OLDPWD", "SHLVL", "SHELL", "TERM", "COLORTERM", "LS_COLORS",
+	"PS1", "PS2", "PROMPT_COMMAND", "DISPLAY", "MAIL", "HOME", "USER", "LOGNAME",
+	"HOSTNAME", "LESSOPEN", "LESSCLOSE", "MOTD_SHOWN", "TMUX", "TMUX_PANE", "STY", "WINDOW",
+	"DBUS_SESSION_BUS_ADDRESS", "INVOCATION_ID", "JOURNAL_STREAM", "NOTIFY_SOCKET",
+	"MANAGERPID", "SYSTEMD_EXEC_PID", "WATCHDOG_PID", "WATCHDOG_USEC",
+	"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES",
+	"SUDO_COMMAND", "SUDO_USER", "SUDO_UID", "SUDO_GID",
+}
+
+// Prefiksy zmiennych sesji (SSH_CONNECTION, XDG_RUNTIME_DIR...)
+var sessionEnvPrefixes = []string{"SSH_", "XDG_", "TERM_", "GPG_", "WAYLAND_"}
+
+// Nazwy zmiennych, których wartości nie powinny trafić do pliku konfiguracji
+var secretEnvPattern = regexp.MustCompile(`(?i)pass(word|wd)?|secret|token|api_?key|private_?key|credential`)
+
+// Odtwarza ustawienia uruchomienia procesu z /proc
+func inferLaunch(p *procInfo, command string, ownEnv map[string]string) LaunchSpec {
+	dir := filepath.Join("/proc", strconv.Itoa(p.PID))
+	var spec LaunchSpec
+
+	// Bez roota cwd i environ cudzych procesów są niedostępne - zostają puste
+	if cwd, err := os.Readlink(filepath.Join(dir, "cwd")); err == nil && !strings.HasSuffix(cwd, " (deleted)") {
+		spec.WorkingDir = cwd
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "environ")); err == nil {
+		spec.Env, spec.SecretEnv = relevantEnv(strings.Split(string(data), "\x00"), ownEnv)
+	}
+
+	if p.UID != os.Getuid() {
+		spec.User = p.User
+	}
+	if u, err := user.LookupId(strconv.Itoa(p.UID)); err == nil && u.Gid != strconv.Itoa(p.GID) {
+		spec.Group = strconv.Itoa(p.GID)
+		if g, err := user.LookupGroupId(spec.Group); err == nil {
+			spec.Group = g.Name
+		}
+	}
+
+	// Przekierowania stdout/stderr do plików (pomijane, gdy robi je sama komenda powłoki)
+	fds := procFDTargets(p.PID)
+	for fd, out := range map[int]*string{1: &spec.Stdout, 2: &spec.Stderr} {
+		target := fds[fd]
+		if !strings.HasPrefix(target, "/") || !isLogCandidatePath(target) || strings.Contains(command, target) {
+			continue
+		}
+		if info, err := os.Stat(target); err == nil && info.Mode().IsRegular() {
+			*out = target
+		}
+	}
+
+	return spec
+}
+
+// Wybiera zmienne opisujące aplikację: bez zmiennych sesji i bez tych, które ma też discovery
+func relevantEnv(environ []string, ownEnv map[string]string) (env, secrets []string) {
+	for _, entry := range environ {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || key == "" || containsString(sessionEnv, key) {
+			continue
+		}
+		if hasAnyPrefix(key, sessionEnvPrefixes) {
+			continue
+		}
+		if own, ok := ownEnv[key]; ok && own == value {
+			continue // Odziedziczona ze wspólnego środowiska, monitor też ją przekaże
+		}
+		if secretEnvPattern.MatchString(key) {
+			secrets = append(secrets, key)
+			continue
+		}
+		env = append(env, entry)
+	}
+	return env, secrets
+}
+
+// Sprawdza czy napis zaczyna się od któregoś z prefiksów
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Środowisko discovery jako mapa
+func environMap() map[string]string {
+	env := make(map[string]string)
+	for _, entry := range os.Environ() {
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// Uzupełnia kandydatów o ustawienia uruchomienia
+func annotateLaunch(candidates []ProcessCandidate, procs []*procInfo) {
+	byPID := make(map[int]*procInfo, len(procs))
+	for _, p := range procs {
+		byPID[p.PID] = p
+	}
+	ownEnv := environMap()
+
+	for i := range candidates {
+		pid, err := strconv.Atoi(candidates[i].PID)
+		if err != nil {
+			continue
+		}
+		if p, ok := byPID[pid]; ok {
+			candidates[i].Launch = inferLaunch(p, candidates[i].Command, ownEnv)
+		}
+		// Dzieci też - użytkownik może wybrać jedno z nich jako korzeń
+		for j := range candidates[i].Children {
+			child := &candidates[i].Children[j].candidate
+			if pid, err := strconv.Atoi(child.PID); err == nil && byPID[pid] != nil {
+				child.Launch = inferLaunch(byPID[pid], child.Command, ownEnv)
+			}
+		}
+	}
+}
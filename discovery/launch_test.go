@@ -0,0 +1,33 @@
+package discovery
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Do konfiguracji trafiają zmienne aplikacji - bez sesji, wspólnego środowiska i sekretów
+func TestRelevantEnv(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin",
+		"LANG=pl_PL.UTF-8",
+		"PWD=/srv/app",
+		"SSH_CONNECTION=10.0.0.1 22",
+		"PORT=8000",
+		"VIRTUAL_ENV=/srv/app/.venv",
+		"DB_PASSWORD=hunter2",
+		"GITHUB_TOKEN=abc",
+		"LANG_OVERRIDE=",
+		"",
+	}
+	own := map[string]string{"PATH": "/usr/bin", "LANG": "en_US.UTF-8"}
+
+	env, secrets := relevantEnv(environ, own)
+
+	wantEnv := []string{"LANG=pl_PL.UTF-8", "PORT=8000", "VIRTUAL_ENV=/srv/app/.venv", "LANG_OVERRIDE="}
+	if !reflect.DeepEqual(env, wantEnv) {
+		t.Errorf("env = %q, oczekiwano %q", env, wantEnv)
+	}
+	if wantSecrets := []string{"DB_PASSWORD", "GITHUB_TOKEN"}; !reflect.DeepEqual(secrets, wantSecrets) {
+		t.Errorf("secrets = %q, oczekiwano %q", secrets, wantSecrets)
+	}
+}
@@ -0,0 +1,170 @@
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Rodzaje miejsc, do których proces zapisuje logi
+const (
+	logKindStdout   = "stdout"   // Plik podpięty pod fd 1
+	logKindStderr   = "stderr"   // Plik podpięty pod fd 2
+	logKindFile     = "file"     // Inny plik otwarty do zapisu
+	logKindJournald = "journald" // Gniazdo journald (stdout usługi lub natywne API)
+	logKindSyslog   = "syslog"   // Gniazdo /dev/log
+)
+
+// Miejsce, do którego proces zapisuje logi
+type LogTarget struct {
+	Path string        // Plik; pusty dla journald i syslog
+	Kind string        // stdout, stderr, file, journald, syslog
+	FD   int           // Deskryptor w procesie
+	Idle time.Duration // Czas od ostatniego zapisu do pliku (0 dla gniazd)
+
+	score int
+}
+
+// Opis do listy kandydatów
+func (t LogTarget) String() string {
+	if t.Path == "" {
+		return fmt.Sprintf("%s (fd %d)", t.Kind, t.FD)
+	}
+	return fmt.Sprintf("%s (%s, ostatni zapis %s temu)", t.Path, t.Kind, formatIdle(t.Idle))
+}
+
+// Czas bezczynności zaokrąglony do czytelnej postaci
+func formatIdle(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%d s", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%d min", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%d h", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d dni", int(d.Hours()/24))
+	}
+}
+
+// Nazwy plików, które wyglądają na logi
+var logPathPattern = regexp.MustCompile(`(?i)\.log(\.\d+)?$|/logs?/|\.out$|\.err$`)
+
+// Pliki otwarte do zapisu, które nie są logami (bazy, blokady, pliki PID)
+var notLogPattern = regexp.MustCompile(`(?i)\.(pid|lock|lck|sock|swp|db|sqlite\d?|db-wal|db-shm|db-journal|wal)$`)
+
+// Gniazda serwerów logów: ścieżka -> rodzaj
+var logSockets = map[string]string{
+	"/run/systemd/journal/stdout":  logKindJournald,
+	"/run/systemd/journal/socket":  logKindJournald,
+	"/run/systemd/journal/dev-log": logKindSyslog,
+	"/run/systemd/journal/syslog":  logKindSyslog,
+	"/dev/log":                     logKindSyslog,
+	"/var/run/syslog":              logKindSyslog,
+}
+
+// Ustala, gdzie proces zapisuje logi, od najbardziej prawdopodobnego miejsca
+//
+// Pod uwagę brane są pliki na fd 1/2, pozostałe pliki otwarte do zapisu oraz
+// gniazda journald/syslog. O kolejności decyduje rodzaj (stdout/stderr, nazwa
+// wyglądająca na log, tryb dopisywania) i to, jak niedawno plik był zapisywany.
+func processLogTargets(p *procInfo, unixPeers map[uint64]string, now time.Time) []LogTarget {
+	var targets []LogTarget
+	seen := make(map[string]bool) // Ten sam plik na kilku deskryptorach (np. 2>&1)
+
+	fds := procFDTargets(p.PID)
+	ordered := make([]int, 0, len(fds))
+	for fd := range fds {
+		ordered = append(ordered, fd)
+	}
+	sort.Ints(ordered) // fd 1 i 2 przed pozostałymi
+
+	for _, fd := range ordered {
+		target := fds[fd]
+
+		if inode, ok := socketInode(target); ok {
+			kind, isLog := logSockets[unixPeers[inode]]
+			if isLog && !seen[kind] {
+				seen[kind] = true
+				targets = append(targets, LogTarget{Kind: kind, FD: fd, score: 10})
+			}
+			continue
+		}
+
+		if !strings.HasPrefix(target, "/") || seen[target] || !isLogCandidatePath(target) {
+			continue
+		}
+		flags, ok := procFDFlags(p.PID, fd)
+		if !ok || flags&syscall.O_ACCMODE == syscall.O_RDONLY {
+			continue // Tylko pliki otwarte do zapisu
+		}
+		info, err := os.Stat(target)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		seen[target] = true
+
+		t := LogTarget{Path: target, Kind: logKindFile, FD: fd}
+		switch fd {
+		case 1:
+			t.Kind = logKindStdout
+			t.score += 30
+		case 2:
+			t.Kind = logKindStderr
+			t.score += 30
+		}
+		if logPathPattern.MatchString(target) {
+			t.score += 20
+		}
+		if flags&syscall.O_APPEND != 0 {
+			t.score += 10
+		}
+
+		t.Idle = now.Sub(info.ModTime())
+		if t.Idle < 0 {
+			t.Idle = 0
+		}
+		switch {
+		case t.Idle < time.Minute:
+			t.score += 25
+		case t.Idle < time.Hour:
+			t.score += 15
+		case t.Idle < 24*time.Hour:
+			t.score += 5
+		}
+
+		targets = append(targets, t)
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].score != targets[j].score {
+			return targets[i].score > targets[j].score
+		}
+		return targets[i].Idle < targets[j].Idle
+	})
+	return targets
+}
+
+// Odrzuca ścieżki, które na pewno nie są logami
+func isLogCandidatePath(path string) bool {
+	for _, prefix := range []string{"/dev/", "/proc/", "/sys/"} {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+	return !strings.HasSuffix(path, " (deleted)") && !notLogPattern.MatchString(path)
+}
+
+// Najbardziej prawdopodobny plik logów (pusty, gdy proces pisze tylko do journald/syslog)
+func bestLogFile(targets []LogTarget) string {
+	for _, t := range targets {
+		if t.Path != "" {
+			return t.Path
+		}
+	}
+	return ""
+}
@@ -0,0 +1,104 @@
+package discovery
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Połączone gniazdo klienta wskazuje ścieżkę serwera (jak stdout usługi -> journald)
+func TestUnixSocketPeers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	server, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	file, err := conn.(*net.UnixConn).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	target, err := os.Readlink("/proc/self/fd/" + strconv.Itoa(int(file.Fd())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inode, ok := socketInode(target)
+	if !ok {
+		t.Fatalf("nieoczekiwany cel deskryptora %q", target)
+	}
+
+	peers := unixSocketPeers()
+	if len(peers) == 0 {
+		t.Skip("sock_diag niedostępne w tym środowisku")
+	}
+	if peers[inode] != path {
+		t.Errorf("rozmówca gniazda %d = %q, oczekiwano %q", inode, peers[inode], path)
+	}
+}
+
+// Pliki na stdout/stderr i otwarte do zapisu są rankingowane; odczytywane i bazy są pomijane
+func TestProcessLogTargets(t *testing.T) {
+	dir := t.TempDir()
+	open := func(name string, flag int) *os.File {
+		f, err := os.OpenFile(filepath.Join(dir, name), flag|os.O_CREATE, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { f.Close() })
+		return f
+	}
+
+	stdout := open("out.txt", os.O_WRONLY|os.O_APPEND)
+	appLog := open("app.log", os.O_WRONLY|os.O_APPEND)
+	readLog := open("read.log", os.O_RDONLY)
+	database := open("data.db", os.O_RDWR)
+
+	// Stary log aplikacji przegrywa z aktywnym stdout
+	old := time.Now().Add(-72 * time.Hour)
+	if err := os.Chtimes(appLog.Name(), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("sleep", "30")
+	cmd.Stdout = stdout
+	cmd.Stderr = stdout
+	cmd.ExtraFiles = []*os.File{appLog, readLog, database}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	targets := processLogTargets(&procInfo{PID: cmd.Process.Pid}, nil, time.Now())
+	if len(targets) != 2 {
+		t.Fatalf("oczekiwano 2 miejsc zapisu, jest %v", targets)
+	}
+	if targets[0].Path != stdout.Name() || targets[0].Kind != logKindStdout {
+		t.Errorf("pierwsze miejsce: %v, oczekiwano %s (stdout)", targets[0], stdout.Name())
+	}
+	if targets[1].Path != appLog.Name() || targets[1].Kind != logKindFile || targets[1].FD != 3 {
+		t.Errorf("drugie miejsce: %v, oczekiwano %s (file, fd 3)", targets[1], appLog.Name())
+	}
+	if bestLogFile(targets) != stdout.Name() {
+		t.Errorf("bestLogFile = %s", bestLogFile(targets))
+	}
+}
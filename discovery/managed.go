@@ -0,0 +1,217 @@
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kto już zarządza procesem (odczytane z /proc/<pid>/cgroup i drzewa procesów)
+type Management struct {
+	Unit          string // Jednostka systemd, np. nginx.service
+	Container     string // Środowisko kontenera: docker, podman, containerd, cri-o, kubernetes, lxc
+	ContainerID   string // Skrócony identyfikator kontenera
+	Supervisor    string // Nadrzędny nadzorca procesów, np. supervisord
+	SupervisorPID int
+}
+
+// Czy proces jest już nadzorowany przez coś innego niż monitor
+func (m Management) Managed() bool {
+	return m.Unit != "" || m.Container != "" || m.Supervisor != ""
+}
+
+// Opis zarządcy do listy kandydatów i raportu (pusty dla procesów niezarządzanych)
+func (m Management) String() string {
+	var parts []string
+	if m.Container != "" {
+		parts = append(parts, fmt.Sprintf("kontener %s %s", m.Container, m.ContainerID))
+	}
+	if m.Unit != "" {
+		parts = append(parts, "systemd "+m.Unit)
+	}
+	if m.Supervisor != "" {
+		parts = append(parts, fmt.Sprintf("%s (PID %d)", m.Supervisor, m.SupervisorPID))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Nadzorcy procesów rozpoznawani po nazwie przodka (comm z /proc/<pid>/comm)
+var supervisorNames = []string{
+	"supervisord",
+	"runsv",
+	"s6-supervise",
+	"circusd",
+	"monitor_mutex",
+}
+
+// Identyfikatory kontenerów w ścieżkach cgroup
+var (
+	containerScopePattern = regexp.MustCompile(`^(docker|libpod|crio|cri-containerd)-([0-9a-f]{12,})\.scope$`)
+	containerIDPattern    = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	kubepodsIDPattern     = regexp.MustCompile(`([0-9a-f]{64})(\.scope)?$`)
+)
+
+// Nazwy środowisk dla prefiksów jednostek .scope
+var containerRuntimes = map[string]string{
+	"docker":         "docker",
+	"libpod":         "podman",
+	"crio":           "cri-o",
+	"cri-containerd": "containerd",
+}
+
+// Skraca identyfikator kontenera do postaci wyświetlanej przez docker ps
+func shortContainerID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// Odczytuje ścieżki cgroup procesu (po jednej na hierarchię, bez duplikatów)
+func readCgroups(pid int) []string {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return nil
+	}
+	return parseCgroups(string(data))
+}
+
+// Parsuje zawartość /proc/<pid>/cgroup: "hierarchia:kontrolery:ścieżka"
+func parseCgroups(data string) []string {
+	var paths []string
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 || fields[2] == "/" || containsString(paths, fields[2]) {
+			continue
+		}
+		paths = append(paths, fields[2])
+	}
+	return paths
+}
+
+// Rozpoznaje kontener na podstawie ścieżki cgroup
+func cgroupContainer(path string) (runtime, id string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, seg := range segments {
+		if m := containerScopePattern.FindStringSubmatch(seg); m != nil {
+			return containerRuntimes[m[1]], shortContainerID(m[2])
+		}
+		if strings.HasPrefix(seg, "kubepods") {
+			if m := kubepodsIDPattern.FindStringSubmatch(segments[len(segments)-1]); m != nil {
+				return "kubernetes", shortContainerID(m[1])
+			}
+		}
+		if strings.HasPrefix(seg, "lxc.payload.") {
+			return "lxc", strings.TrimPrefix(seg, "lxc.payload.")
+		}
+		if i+1 < len(segments) {
+			next := segments[i+1]
+			switch {
+			case seg == "docker" && containerIDPattern.MatchString(next):
+				return "docker", shortContainerID(next)
+			case seg == "lxc":
+				return "lxc", next
+			}
+		}
+	}
+	return "", ""
+}
+
+// Zwraca jednostkę systemd (.service) ze ścieżki cgroup
+//
+// Procesy w sesji logowania (session-N.scope) lub w zakresach aplikacji menedżera
+// użytkownika (user@UID.service/app.slice/*.scope) nie są restartowane przez systemd,
+// więc nie są traktowane jako zarządzane.
+func cgroupUnit(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		seg := segments[i]
+		if !strings.HasSuffix(seg, ".service") {
+			continue
+		}
+		if strings.HasPrefix(seg, "user@") && i < len(segments)-1 && segments[i+1] != "init.scope" {
+			return "" // Proces w zakresie aplikacji, a nie w usłudze użytkownika
+		}
+		return seg
+	}
+	return ""
+}
+
+// Ustala, kto zarządza procesem: kontener, jednostka systemd lub nadzorca nadrzędny
+func detectManagement(p *procInfo, byPID map[int]*procInfo, own Management) Management {
+	var m Management
+
+	for _, path := range p.Cgroups {
+		if m.Container == "" {
+			m.Container, m.ContainerID = cgroupContainer(path)
+		}
+		if m.Unit == "" {
+			m.Unit = cgroupUnit(path)
+		}
+	}
+
+	// Procesy z tego samego kontenera co discovery nie są "cudze" - pilnuje ich ten sam monitor
+	if m.Container != "" && m.Container == own.Container && m.ContainerID == own.ContainerID {
+		m.Container, m.ContainerID = "", ""
+	}
+
+	// Przodkowie aż do init (z ochroną przed pętlą przy wyścigu ze zmianą PID)
+	seen := map[int]bool{p.PID: true}
+	for ppid := p.PPID; ppid > 1 && !seen[ppid]; {
+		seen[ppid] = true
+		parent, ok := byPID[ppid]
+		if !ok {
+			break
+		}
+		if isSupervisorName(parent.Comm) {
+			m.Supervisor = parent.Comm
+			m.SupervisorPID = parent.PID
+			break
+		}
+		ppid = parent.PPID
+	}
+
+	return m
+}
+
+// Sprawdza czy nazwa procesu należy do znanego nadzorcy
+func isSupervisorName(comm string) bool {
+	if strings.HasPrefix(comm, "PM2 ") { // Demon pm2 zmienia nazwę na "PM2 vX.Y.Z: God Daemon"
+		return true
+	}
+	return containsString(supervisorNames, comm)
+}
+
+// Kontener, w którym działa samo discovery
+func ownManagement() Management {
+	var m Management
+	for _, path := range readCgroups(os.Getpid()) {
+		if m.Container == "" {
+			m.Container, m.ContainerID = cgroupContainer(path)
+		}
+	}
+	return m
+}
+
+// Uzupełnia kandydatów o ścieżki cgroup i informacje o zarządcy
+func annotateManagement(candidates []ProcessCandidate, procs []*procInfo) {
+	byPID := make(map[int]*procInfo, len(procs))
+	for _, p := range procs {
+		byPID[p.PID] = p
+	}
+	own := ownManagement()
+
+	for i := range candidates {
+		pid, err := strconv.Atoi(candidates[i].PID)
+		if err != nil {
+			continue
+		}
+		if p, ok := byPID[pid]; ok {
+			candidates[i].Cgroups = p.Cgroups
+			candidates[i].ManagedBy = detectManagement(p, byPID, own)
+		}
+	}
+}
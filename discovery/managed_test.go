@@ -0,0 +1,84 @@
+package discovery
+
+import "testing"
+
+// Jednostka systemd i kontener rozpoznawane ze ścieżek cgroup
+func TestCgroupManagement(t *testing.T) {
+	const id = "3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e"
+
+	tests := []struct {
+		path      string
+		unit      string
+		container string
+		shortID   string
+	}{
+		{path: "/system.slice/nginx.service", unit: "nginx.service"},
+		{path: "/system.slice/docker-" + id + ".scope", container: "docker", shortID: id[:12]},
+		{path: "/docker/" + id, container: "docker", shortID: id[:12]},
+		{path: "/machine.slice/libpod-" + id + ".scope/container", container: "podman", shortID: id[:12]},
+		{path: "/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1.slice/cri-containerd-" + id + ".scope", container: "kubernetes", shortID: id[:12]},
+		{path: "/kubepods/besteffort/pod1234/" + id, container: "kubernetes", shortID: id[:12]},
+		{path: "/system.slice/cri-containerd-" + id + ".scope", container: "containerd", shortID: id[:12]},
+		{path: "/lxc.payload.web01/system.slice/app.service", unit: "app.service", container: "lxc", shortID: "web01"},
+		{path: "/user.slice/user-1000.slice/user@1000.service/app.slice/worker.service", unit: "worker.service"},
+		{path: "/user.slice/user-1000.slice/user@1000.service/init.scope", unit: "user@1000.service"},
+		// Sesja logowania i zakresy aplikacji nie są restartowane przez systemd
+		{path: "/user.slice/user-1000.slice/session-3.scope"},
+		{path: "/user.slice/user-1000.slice/user@1000.service/app.slice/app-gnome-terminal-42.scope"},
+		{path: "/process_api/sandbox"},
+	}
+
+	for _, tt := range tests {
+		if unit := cgroupUnit(tt.path); unit != tt.unit {
+			t.Errorf("cgroupUnit(%q) = %q, oczekiwano %q", tt.path, unit, tt.unit)
+		}
+		container, shortID := cgroupContainer(tt.path)
+		if container != tt.container || shortID != tt.shortID {
+			t.Errorf("cgroupContainer(%q) = %q %q, oczekiwano %q %q", tt.path, container, shortID, tt.container, tt.shortID)
+		}
+	}
+}
+
+// Wpisy wszystkich hierarchii bez korzenia i powtórzeń
+func TestParseCgroups(t *testing.T) {
+	data := "12:pids:/system.slice/app.service\n" +
+		"4:memory:/system.slice/app.service\n" +
+		"3:cpuset:/\n" +
+		"1:name=systemd:/system.slice/app.service\n" +
+		"0::/system.slice/app.service\n"
+
+	paths := parseCgroups(data)
+	if len(paths) != 1 || paths[0] != "/system.slice/app.service" {
+		t.Fatalf("parseCgroups = %q", paths)
+	}
+	if paths := parseCgroups("0::/\n"); len(paths) != 0 {
+		t.Errorf("korzeń nie powinien być zwracany: %q", paths)
+	}
+}
+
+// Przodek będący nadzorcą oznacza proces jako zarządzany; własny kontener discovery jest pomijany
+func TestDetectManagement(t *testing.T) {
+	const id = "3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e"
+
+	byPID := map[int]*procInfo{
+		1:  {PID: 1, Comm: "init"},
+		10: {PID: 10, PPID: 1, Comm: "supervisord"},
+		11: {PID: 11, PPID: 10, Comm: "sh"},
+		12: {PID: 12, PPID: 11, Comm: "worker", Cgroups: []string{"/docker/" + id}},
+		20: {PID: 20, PPID: 1, Comm: "app"},
+	}
+
+	m := detectManagement(byPID[12], byPID, Management{})
+	if m.Supervisor != "supervisord" || m.SupervisorPID != 10 || m.Container != "docker" {
+		t.Errorf("oczekiwano supervisord (PID 10) w kontenerze docker, jest %+v", m)
+	}
+
+	own := Management{Container: "docker", ContainerID: id[:12]}
+	if m := detectManagement(byPID[12], byPID, own); m.Container != "" {
+		t.Errorf("kontener discovery nie powinien być traktowany jako zarządca: %+v", m)
+	}
+
+	if m := detectManagement(byPID[20], byPID, Management{}); m.Managed() {
+		t.Errorf("proces bez zarządcy oznaczony jako zarządzany: %+v", m)
+	}
+}
@@ -0,0 +1,208 @@
+package discovery
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"monitor_mutex/config"
+)
+
+// Wynik scalania wykrytych procesów z istniejącym plikiem
+type ConfigMerge struct {
+	data     []byte                 // Istniejący plik
+	original []string               // Linie istniejącego pliku
+	merged   []string               // Linie po scaleniu
+	insertAt int                    // Indeks pierwszej wstawionej linii
+	inserted int                    // Liczba wstawionych linii
+	Added    []config.ProcessConfig // Nowe wpisy
+	Kept     []string               // Pominięte wpisy: "nazwa (powód)"
+}
+
+// Początek listy processes w stylu blokowym (dopuszcza komentarz po dwukropku)
+var processesKeyPattern = regexp.MustCompile(`^processes:\s*(#.*)?$`)
+
+// Scala wykryte procesy z istniejącym plikiem konfiguracji.
+// Plik jest zmieniany tekstowo - nowe wpisy są dopisywane na końcu listy processes,
+// więc komentarze, kolejność i nieznane discovery pola zostają nietknięte.
+// Zmienne ${...} nie są podstawiane - scalanie nie wymaga środowiska monitora.
+func MergeConfiguration(data []byte, configs []config.ProcessConfig) (*ConfigMerge, error) {
+	existing, err := config.ParseRaw(data)
+	if err != nil {
+		return nil, fmt.Errorf("nie można sparsować istniejącej konfiguracji: %v", err)
+	}
+
+	m := &ConfigMerge{data: data, original: splitLines(string(data))}
+
+	// Dopasowanie po nazwie lub komendzie
+	names := make(map[string]bool)
+	commands := make(map[string]bool)
+	for _, p := range existing.Processes {
+		names[p.Name] = true
+		commands[p.Command] = true
+	}
+	for _, config := range configs {
+		switch {
+		case names[config.Name]:
+			m.Kept = append(m.Kept, fmt.Sprintf("%s (nazwa już w konfiguracji)", config.Name))
+		case commands[config.Command]:
+			m.Kept = append(m.Kept, fmt.Sprintf("%s (komenda już w konfiguracji)", config.Name))
+		default:
+			m.Added = append(m.Added, config)
+			names[config.Name] = true
+			commands[config.Command] = true
+		}
+	}
+
+	if len(m.Added) == 0 {
+		m.merged = m.original
+		return m, nil
+	}
+
+	// Znajdź listę processes i miejsce na nowe wpisy
+	start := -1
+	for i, line := range m.original {
+		if processesKeyPattern.MatchString(strings.TrimRight(line, " \t")) {
+			start = i
+			break
+		}
+	}
+
+	var entries []string
+	if start < 0 {
+		if strings.HasPrefix(strings.TrimSpace(string(data)), "{") || hasTopLevelKey(m.original, "processes") {
+			return nil, fmt.Errorf("lista processes nie jest w stylu blokowym - scalanie niemożliwe")
+		}
+		// Brak listy - dopisz ją na końcu pliku
+		m.insertAt = len(m.original)
+		if m.insertAt > 0 && strings.TrimSpace(m.original[m.insertAt-1]) != "" {
+			entries = append(entries, "")
+		}
+		entries = append(entries, "processes:")
+		rendered, err := renderEntries(m.Added, "  ", false)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, rendered...)
+	} else {
+		indent := "  "
+		found := false
+		m.insertAt = start + 1
+		for i := start + 1; i < len(m.original); i++ {
+			line := m.original[i]
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || strings.HasPrefix(line, "#") {
+				continue // Komentarze od pierwszej kolumny nie należą do listy
+			}
+			if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "-") {
+				break // Następny klucz najwyższego poziomu
+			}
+			if !found && strings.HasPrefix(trimmed, "- ") {
+				indent = line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+				found = true
+			}
+			m.insertAt = i + 1
+		}
+		if entries, err = renderEntries(m.Added, indent, m.insertAt > start+1); err != nil {
+			return nil, err
+		}
+	}
+
+	m.inserted = len(entries)
+	m.merged = append(append(append([]string(nil), m.original[:m.insertAt]...), entries...), m.original[m.insertAt:]...)
+	return m, nil
+}
+
+// Renderuje wpisy processes jako linie tekstu
+func renderEntries(configs []config.ProcessConfig, indent string, separate bool) ([]string, error) {
+	var lines []string
+	for i, pc := range configs {
+		if separate || i > 0 {
+			lines = append(lines, "")
+		}
+		entry, err := config.MarshalProcess(pc, indent)
+		if err != nil {
+			return nil, fmt.Errorf("błąd kodowania YAML: %v", err)
+		}
+		lines = append(lines, entry...)
+	}
+	return lines, nil
+}
+
+// Sprawdza czy plik zawiera klucz najwyższego poziomu
+func hasTopLevelKey(lines []string, key string) bool {
+	for _, line := range lines {
+		if strings.HasPrefix(line, key+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// Dzieli tekst na linie bez końcowej pustej linii
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// Wypisuje podsumowanie i różnice w formacie unified diff
+func (m *ConfigMerge) PrintDiff(w io.Writer, filename string) {
+	for _, kept := range m.Kept {
+		fmt.Fprintf(w, "   = %s - bez zmian\n", kept)
+	}
+	for _, config := range m.Added {
+		fmt.Fprintf(w, "   + %s\n", config.Name)
+	}
+	if len(m.Added) == 0 {
+		return
+	}
+
+	const context = 3
+	from := m.insertAt - context
+	if from < 0 {
+		from = 0
+	}
+	to := m.insertAt + context
+	if to > len(m.original) {
+		to = len(m.original)
+	}
+
+	fmt.Fprintf(w, "\n--- %s\n+++ %s (po scaleniu)\n", filename, filename)
+	fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", from+1, to-from, from+1, to-from+m.inserted)
+	for _, line := range m.original[from:m.insertAt] {
+		fmt.Fprintf(w, " %s\n", line)
+	}
+	for _, line := range m.merged[m.insertAt : m.insertAt+m.inserted] {
+		fmt.Fprintf(w, "+%s\n", line)
+	}
+	for _, line := range m.original[m.insertAt:to] {
+		fmt.Fprintf(w, " %s\n", line)
+	}
+}
+
+// Zawartość pliku po scaleniu
+func (m *ConfigMerge) content() []byte {
+	return []byte(strings.Join(m.merged, "\n") + "\n")
+}
+
+// Zapisuje scaloną konfigurację: kopia zapasowa .bak, potem podmiana pliku - oba zapisy atomowe
+func WriteMergedConfiguration(filename string, m *ConfigMerge) (string, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return "", err
+	}
+
+	backup := filename + ".bak"
+	if err := config.WriteFileAtomic(backup, m.data, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("nie można utworzyć kopii zapasowej %s: %v", backup, err)
+	}
+
+	if err := config.WriteFileAtomic(filename, m.content(), info.Mode().Perm()); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
@@ -0,0 +1,108 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"monitor_mutex/config"
+)
+
+// Istniejący plik z komentarzami i kluczem po liście processes
+const existingConfig = `# Konfiguracja produkcyjna - nie usuwać komentarzy
+state_file: /var/lib/monitor_mutex/state.json
+
+processes: # lista monitorowanych procesów
+  # API za nginx
+  - name: api
+    command: /usr/bin/api --port 8080 # port z load balancera
+    log_file: /var/log/api.log
+    working_dir: ${MONITOR_MUTEX_TEST_API_HOME} # ustawiane w środowisku monitora
+    timeout: 60
+
+# Globalne powiadomienia
+notifications:
+  max_per_hour: 5
+`
+
+// Nowe wpisy trafiają na koniec listy, a reszta pliku i kopia .bak zostają nietknięte.
+// Nieustawiona zmienna w istniejącym pliku nie blokuje scalania.
+func TestMergeConfiguration(t *testing.T) {
+	configs := []config.ProcessConfig{
+		{Name: "api", Command: "/usr/bin/api --port 9090", LogFile: "/var/log/api2.log", Timeout: 30, Interval: 5},
+		{Name: "api-copy", Command: "/usr/bin/api --port 8080", LogFile: "/var/log/api.log", Timeout: 30, Interval: 5},
+		{Name: "worker", Command: "/usr/bin/worker --queue jobs", LogFile: "/var/log/worker.log", Timeout: 120, Interval: 10},
+	}
+
+	m, err := MergeConfiguration([]byte(existingConfig), configs)
+	if err != nil {
+		t.Fatalf("MergeConfiguration: %v", err)
+	}
+	if len(m.Added) != 1 || m.Added[0].Name != "worker" {
+		t.Fatalf("dodane wpisy: %+v", m.Added)
+	}
+	if len(m.Kept) != 2 || !strings.HasPrefix(m.Kept[0], "api (nazwa") || !strings.HasPrefix(m.Kept[1], "api-copy (komenda") {
+		t.Errorf("pominięte wpisy: %v", m.Kept)
+	}
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "monitor_config.yaml")
+	if err := os.WriteFile(filename, []byte(existingConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Stara kopia zapasowa jest podmieniana w całości
+	if err := os.WriteFile(filename+".bak", []byte(strings.Repeat("stara kopia\n", 100)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	backup, err := WriteMergedConfiguration(filename, m)
+	if err != nil {
+		t.Fatalf("WriteMergedConfiguration: %v", err)
+	}
+
+	if data, err := os.ReadFile(backup); err != nil || string(data) != existingConfig {
+		t.Errorf("kopia zapasowa %s: %v\n%s", backup, err, data)
+	}
+	if info, err := os.Stat(backup); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("uprawnienia kopii zapasowej: %v %v", info, err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(filename); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("uprawnienia po scaleniu: %v %v", info, err)
+	}
+
+	// Wszystkie linie oryginału zostają w tej samej kolejności, nowy wpis stoi przed notifications
+	merged := string(data)
+	rest := merged
+	for _, line := range splitLines(existingConfig) {
+		i := strings.Index(rest, line+"\n")
+		if i < 0 {
+			t.Fatalf("brak linii %q (lub zmieniona kolejność) w:\n%s", line, merged)
+		}
+		rest = rest[i+len(line)+1:]
+	}
+	worker := strings.Index(merged, "- name: worker")
+	if worker < 0 || worker > strings.Index(merged, "# Globalne powiadomienia") || worker < strings.Index(merged, "timeout: 60") {
+		t.Errorf("nowy wpis w złym miejscu:\n%s", merged)
+	}
+
+	cfg, err := config.ParseRaw(data)
+	if err != nil {
+		t.Fatalf("scalony plik nie parsuje się: %v\n%s", err, merged)
+	}
+	if len(cfg.Processes) != 2 || cfg.Processes[0].WorkingDir != "${MONITOR_MUTEX_TEST_API_HOME}" ||
+		cfg.Processes[1].Command != "/usr/bin/worker --queue jobs" || cfg.Notifications.MaxPerHour != 5 {
+		t.Errorf("scalona konfiguracja: %+v", cfg)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("w katalogu zostały pliki tymczasowe: %v", entries)
+	}
+}
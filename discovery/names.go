@@ -0,0 +1,124 @@
+package discovery
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Interpretery, dla których nazwą usługi jest uruchamiany skrypt, moduł lub jar
+var interpreterPattern = regexp.MustCompile(`^(sh|bash|dash|zsh|ksh|python[0-9.]*|node|nodejs|deno|bun|ruby[0-9.]*|perl[0-9.]*|php[0-9.]*|java)$`)
+
+// Flagi interpreterów z wartością w osobnym argumencie
+var interpreterValueFlags = []string{"-cp", "-classpath", "--class-path", "-W", "-X", "-r", "--require", "-I", "-e"}
+
+// Rozszerzenia skryptów usuwane z nazwy
+var scriptExtensions = []string{".py", ".sh", ".js", ".mjs", ".cjs", ".ts", ".rb", ".pl", ".php", ".jar"}
+
+// Nazwy plików mówiące mało o usłudze - wtedy liczy się katalog (shop/index.js -> shop)
+var genericScriptNames = []string{"index", "main", "__main__", "run", "start"}
+
+// Proponuje nazwę wpisu konfiguracji: skrypt lub moduł zamiast nazwy interpretera
+func serviceName(candidate ProcessCandidate) string {
+	if name := commandServiceName(strings.Fields(candidate.Command)); name != "" {
+		return name
+	}
+	return sanitizeName(candidate.Name)
+}
+
+// Nazwa usługi z argumentów komendy ("" gdy nie da się jej ustalić)
+func commandServiceName(fields []string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	program := path.Base(fields[0])
+	if !interpreterPattern.MatchString(program) {
+		return sanitizeName(program)
+	}
+
+	for i := 1; i < len(fields); i++ {
+		arg := fields[i]
+		switch {
+		case arg == "-c" && i+1 < len(fields) && !strings.HasPrefix(program, "java"):
+			// sh -c "python3 app.py >> app.log" - nazwa z wewnętrznej komendy
+			return commandServiceName(fields[i+1:])
+		case arg == "-m" && i+1 < len(fields):
+			return sanitizeName(fields[i+1]) // python -m http.server
+		case arg == "-jar" && i+1 < len(fields):
+			return scriptName(fields[i+1])
+		case containsString(interpreterValueFlags, arg):
+			i++
+		case strings.HasPrefix(arg, "-"):
+			continue
+		default:
+			if program == "java" {
+				// Klasa główna: com.example.Shop -> Shop
+				return sanitizeName(arg[strings.LastIndex(arg, ".")+1:])
+			}
+			return scriptName(arg)
+		}
+	}
+	return sanitizeName(program)
+}
+
+// Nazwa skryptu bez katalogu i rozszerzenia
+func scriptName(file string) string {
+	name := path.Base(file)
+	for _, ext := range scriptExtensions {
+		if strings.HasSuffix(name, ext) && len(name) > len(ext) {
+			name = strings.TrimSuffix(name, ext)
+			break
+		}
+	}
+	if dir := path.Base(path.Dir(file)); containsString(genericScriptNames, name) && dir != "." && dir != "/" && dir != "bin" && dir != "src" {
+		name = dir
+	}
+	return sanitizeName(name)
+}
+
+// Zastępuje znaki, które źle wyglądają w nazwie wpisu i pliku logu
+func sanitizeName(name string) string {
+	name = strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r == '/' || r == ' ' || r == ':' || r == '\t':
+			return '-'
+		case r < ' ':
+			return -1
+		}
+		return r
+	}, name), "-")
+	return name
+}
+
+// Nadaje kandydatom unikalne nazwy wpisów konfiguracji
+//
+// Powtarzające się nazwy są rozróżniane portem (python-8000, python-8001),
+// a gdy to nie wystarcza - kolejnym numerem (worker, worker-2).
+func assignConfigNames(candidates []ProcessCandidate) {
+	counts := make(map[string]int)
+	for i := range candidates {
+		candidates[i].ConfigName = serviceName(candidates[i])
+		counts[candidates[i].ConfigName]++
+	}
+	for i := range candidates {
+		if counts[candidates[i].ConfigName] > 1 && candidates[i].Port != "" {
+			candidates[i].ConfigName += "-" + candidates[i].Port
+		}
+	}
+
+	used := make(map[string]bool)
+	for i := range candidates {
+		candidates[i].ConfigName = uniqueName(candidates[i].ConfigName, used)
+	}
+}
+
+// Zwraca nazwę nieużytą wcześniej, w razie potrzeby z numerem; zapamiętuje ją w used
+func uniqueName(name string, used map[string]bool) string {
+	unique := name
+	for n := 2; used[unique]; n++ {
+		unique = name + "-" + strconv.Itoa(n)
+	}
+	used[unique] = true
+	return unique
+}
@@ -0,0 +1,79 @@
+package discovery
+
+import "testing"
+
+// Nazwa wpisu pochodzi ze skryptu, modułu lub jara, a nie z interpretera
+func TestServiceName(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{"bash", "/bin/bash /srv/app/app.sh", "app"},
+		{"python3", "python3 -u worker.py --queue high", "worker"},
+		{"python3", "/usr/bin/python3.11 -m http.server 8000", "http.server"},
+		{"sh", "sh -c python3 -m http.server 8765 >> /tmp/web.log 2>&1", "http.server"},
+		{"node", "node --max-old-space-size=512 /srv/shop/index.js", "shop"},
+		{"java", "java -Xmx1g -jar /opt/billing/billing-2.1.jar", "billing-2.1"},
+		{"java", "java -cp lib/* com.example.Gateway", "Gateway"},
+		{"redis-server", "/usr/bin/redis-server *:6379", "redis-server"},
+		{"myapp", "", "myapp"},
+	}
+
+	for _, tt := range tests {
+		got := serviceName(ProcessCandidate{Name: tt.name, Command: tt.command})
+		if got != tt.want {
+			t.Errorf("serviceName(%q) = %q, oczekiwano %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+// Powtórzone nazwy dostają port, a bez portu kolejny numer
+func TestAssignConfigNames(t *testing.T) {
+	candidates := []ProcessCandidate{
+		{Name: "python3", Command: "python3 api.py", Port: "8000"},
+		{Name: "python3", Command: "python3 api.py", Port: "8001"},
+		{Name: "bash", Command: "bash worker.sh"},
+		{Name: "bash", Command: "bash worker.sh"},
+		{Name: "nginx", Command: "nginx -g daemon off;", Port: "80"},
+	}
+	assignConfigNames(candidates)
+
+	want := []string{"api-8000", "api-8001", "worker", "worker-2", "nginx"}
+	for i, c := range candidates {
+		if c.ConfigName != want[i] {
+			t.Errorf("kandydat %d: ConfigName = %q, oczekiwano %q", i, c.ConfigName, want[i])
+		}
+	}
+}
+
+// Ten sam PID z trzech metod wykrycia daje jeden wpis z portem, logiem i CPU
+func TestRemoveDuplicates(t *testing.T) {
+	candidates := []ProcessCandidate{
+		{Name: "python3", PID: "42", Command: "python3 api.py", LogFile: "/srv/api.log", LogTargets: []LogTarget{{Path: "/srv/api.log", Kind: logKindFile, FD: 3}}, Sources: []string{sourceLog}},
+		{Name: "python3", PID: "42", Command: "python3 api.py", Port: "8000", User: "app", Sources: []string{sourcePort}},
+		{Name: "python3", PID: "42", Command: "python3 api.py", CPUUsage: "1.5", MemoryUsage: "0.3", Sources: []string{sourceLongRunning}},
+		{Name: "python3", PID: "43", Command: "python3 api.py", Port: "8001", Sources: []string{sourcePort}},
+		{Name: "", PID: "44", Command: "/opt/bin/gateway --listen :9000", Port: "9000", Sources: []string{sourcePort}},
+		{Name: "kworker", PID: "45", Command: "", Sources: []string{sourceLongRunning}},
+	}
+
+	got := removeDuplicates(candidates)
+	if len(got) != 3 {
+		t.Fatalf("oczekiwano 3 kandydatów, jest %d: %+v", len(got), got)
+	}
+
+	api := got[0]
+	if api.Port != "8000" || api.LogFile != "/srv/api.log" || api.User != "app" || api.CPUUsage != "1.5" {
+		t.Errorf("połączony kandydat: %+v", api)
+	}
+	if len(api.Sources) != 3 {
+		t.Errorf("Sources = %v, oczekiwano trzech metod", api.Sources)
+	}
+	if got[1].PID != "43" || got[1].Port != "8001" {
+		t.Errorf("drugi proces z tą samą komendą powinien zostać osobno: %+v", got[1])
+	}
+	if got[2].Name != "gateway" {
+		t.Errorf("nazwa z komendy: %q, oczekiwano gateway", got[2].Name)
+	}
+}
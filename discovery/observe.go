@@ -0,0 +1,183 @@
+package discovery
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// Rytm zapisów do logu zaobserwowany przez ObserveLogs
+type LogCadence struct {
+	Window   time.Duration // Czas obserwacji
+	Writes   int           // Liczba zauważonych zapisów
+	Median   time.Duration // Mediana przerw między zapisami
+	P95      time.Duration // 95. percentyl przerw
+	Max      time.Duration // Najdłuższa cisza, łącznie z tą na końcu obserwacji
+	Timeout  int           // Proponowany timeout w sekundach (0 = brak propozycji)
+	Interval int           // Proponowany interval w sekundach
+	Bursty   bool          // Zapisy zbyt nieregularne, by cisza w logu oznaczała zawieszenie
+	Note     string        // Dlaczego nie ma propozycji
+}
+
+// Parametry wyliczania timeoutu z obserwacji
+const (
+	observeTick        = time.Second // Co ile sprawdzany jest rozmiar logów
+	observeMinWrites   = 5           // Mniej zapisów to za mało, by mówić o rytmie
+	observeMargin      = 2.0         // Timeout = margines × 95. percentyl ciszy
+	observeMaxMargin   = 1.5         // ...ale nie mniej niż margines × najdłuższa zaobserwowana cisza
+	observeMinTimeout  = 10          // Nie proponuj timeoutu krótszego niż 10 s
+	burstyRatio        = 10.0        // p95 / mediana powyżej tego = zapisy seriami
+	burstyVariation    = 1.5         // Współczynnik zmienności przerw powyżej tego = zapisy losowe
+	intervalPerTimeout = 6           // Interval ~ 1/6 timeoutu: kilka sprawdzeń przed restartem
+)
+
+// Obserwuje przez podany czas przyrost logów kandydatów i ustawia im Cadence
+func ObserveLogs(candidates []ProcessCandidate, window time.Duration) {
+	type watched struct {
+		index  int
+		path   string
+		size   int64
+		mtime  time.Time
+		writes []time.Time
+	}
+
+	var files []*watched
+	for i, candidate := range candidates {
+		if candidate.LogFile == "" {
+			continue
+		}
+		info, err := os.Stat(candidate.LogFile)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, &watched{index: i, path: candidate.LogFile, size: info.Size(), mtime: info.ModTime()})
+	}
+	if len(files) == 0 {
+		fmt.Println("   Brak plików logów do obserwacji")
+		return
+	}
+
+	fmt.Printf("⏱️  Obserwacja %d logów przez %s...\n", len(files), window)
+	start := time.Now()
+	ticker := time.NewTicker(observeTick)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		for _, f := range files {
+			info, err := os.Stat(f.path)
+			if err != nil {
+				continue // Rotacja w toku - plik wróci przy następnym sprawdzeniu
+			}
+			// Zmiana rozmiaru lub czasu modyfikacji (także zmniejszenie po rotacji) to zapis
+			if info.Size() != f.size || !info.ModTime().Equal(f.mtime) {
+				f.writes = append(f.writes, now)
+				f.size, f.mtime = info.Size(), info.ModTime()
+			}
+		}
+		if now.Sub(start) >= window {
+			break
+		}
+	}
+
+	end := time.Now()
+	for _, f := range files {
+		cadence := analyzeCadence(f.writes, start, end)
+		candidates[f.index].Cadence = &cadence
+	}
+}
+
+// Wylicza rytm zapisów i proponowany timeout z chwil zapisów w oknie [start, end]
+func analyzeCadence(writes []time.Time, start, end time.Time) LogCadence {
+	c := LogCadence{Window: end.Sub(start), Writes: len(writes)}
+
+	// Cisza na początku i końcu okna jest niepełna - liczy się tylko do maksimum, nie do percentyli
+	last := start
+	var gaps []time.Duration
+	for i, w := range writes {
+		if i > 0 {
+			gaps = append(gaps, w.Sub(last))
+		}
+		if gap := w.Sub(last); gap > c.Max {
+			c.Max = gap
+		}
+		last = w
+	}
+	if trailing := end.Sub(last); trailing > c.Max {
+		c.Max = trailing
+	}
+
+	if len(writes) < observeMinWrites {
+		c.Note = fmt.Sprintf("za mało zapisów (%d w %s)", len(writes), c.Window.Round(time.Second))
+		return c
+	}
+
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+	c.Median = percentile(gaps, 50)
+	c.P95 = percentile(gaps, 95)
+
+	if (c.Median > 0 && float64(c.P95)/float64(c.Median) > burstyRatio) || variation(gaps) > burstyVariation {
+		c.Bursty = true
+		c.Note = "zapisy seriami - cisza w logu nie musi oznaczać zawieszenia"
+		return c
+	}
+
+	// Najdłuższa cisza (także na początku i końcu okna) właśnie się zdarzyła - timeout
+	// krótszy od niej restartowałby proces przy pierwszej takiej przerwie
+	c.Timeout = int(math.Ceil(observeMargin * c.P95.Seconds()))
+	if silence := int(math.Ceil(observeMaxMargin * c.Max.Seconds())); silence > c.Timeout {
+		c.Timeout = silence
+	}
+	if c.Timeout < observeMinTimeout {
+		c.Timeout = observeMinTimeout
+	}
+	c.Interval = c.Timeout / intervalPerTimeout
+	if c.Interval < 1 {
+		c.Interval = 1
+	}
+	return c
+}
+
+// Percentyl metodą najbliższej rangi (gaps posortowane rosnąco)
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Współczynnik zmienności (odchylenie standardowe / średnia) przerw
+func variation(gaps []time.Duration) float64 {
+	if len(gaps) < 2 {
+		return 0
+	}
+	var sum float64
+	for _, g := range gaps {
+		sum += g.Seconds()
+	}
+	mean := sum / float64(len(gaps))
+	if mean == 0 {
+		return 0
+	}
+	var sq float64
+	for _, g := range gaps {
+		d := g.Seconds() - mean
+		sq += d * d
+	}
+	return math.Sqrt(sq/float64(len(gaps))) / mean
+}
+
+// Opis rytmu do podsumowania konfiguracji
+func (c LogCadence) String() string {
+	if c.Timeout == 0 {
+		return c.Note
+	}
+	return fmt.Sprintf("%d zapisów w %s, mediana ciszy %s, 95. percentyl %s, najdłuższa %s",
+		c.Writes, c.Window.Round(time.Second), c.Median.Round(100*time.Millisecond),
+		c.P95.Round(100*time.Millisecond), c.Max.Round(100*time.Millisecond))
+}
@@ -0,0 +1,67 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+)
+
+// Chwile zapisów: start + kolejne przerwy
+func writesAt(start time.Time, gaps ...time.Duration) []time.Time {
+	var writes []time.Time
+	t := start
+	for _, g := range gaps {
+		t = t.Add(g)
+		writes = append(writes, t)
+	}
+	return writes
+}
+
+func repeat(n int, gaps ...time.Duration) []time.Duration {
+	var out []time.Duration
+	for i := 0; i < n; i++ {
+		out = append(out, gaps...)
+	}
+	return out
+}
+
+func TestAnalyzeCadence(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Minute)
+
+	// Zapis co 20 s (z drobnym rozrzutem) - timeout 2 × p95
+	c := analyzeCadence(writesAt(start, repeat(3, 19*time.Second, 20*time.Second, 21*time.Second)...), start, end)
+	if c.Bursty || c.Timeout != 42 || c.Interval != 7 || c.P95 != 21*time.Second {
+		t.Errorf("regularny log: %+v", c)
+	}
+
+	// Zapis co sekundę przez całe okno - timeout nie schodzi poniżej minimum
+	c = analyzeCadence(writesAt(start, repeat(120, time.Second)...), start, end)
+	if c.Timeout != observeMinTimeout || c.Interval != 1 {
+		t.Errorf("częsty log: %+v", c)
+	}
+
+	// Zapis co sekundę przez 20 s, potem 100 s ciszy do końca okna - timeout od najdłuższej
+	// ciszy, a nie od przerw między zapisami (2 × p95 dałoby minimum 10 s)
+	c = analyzeCadence(writesAt(start, repeat(20, time.Second)...), start, end)
+	if c.Bursty || c.Max != 100*time.Second || c.Timeout != 150 || c.Interval != 25 {
+		t.Errorf("długa cisza na końcu okna: %+v", c)
+	}
+
+	// Długa cisza przed pierwszym zapisem liczy się tak samo
+	c = analyzeCadence(writesAt(start, append([]time.Duration{80 * time.Second}, repeat(30, time.Second)...)...), start, end)
+	if c.Bursty || c.Max != 80*time.Second || c.Timeout != 120 {
+		t.Errorf("długa cisza na początku okna: %+v", c)
+	}
+
+	// Serie po kilka zapisów i długa cisza - bez propozycji, z ostrzeżeniem
+	c = analyzeCadence(writesAt(start, repeat(3, time.Second, time.Second, time.Second, time.Second, 35*time.Second)...), start, end)
+	if !c.Bursty || c.Timeout != 0 {
+		t.Errorf("log seriami: %+v", c)
+	}
+
+	// Za mało zapisów
+	c = analyzeCadence(writesAt(start, 30*time.Second, 30*time.Second), start, end)
+	if c.Timeout != 0 || c.Bursty || c.Note == "" || c.Max != time.Minute {
+		t.Errorf("mało zapisów: %+v", c)
+	}
+}
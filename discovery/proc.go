@@ -0,0 +1,275 @@
+package discovery
+
+import (
+	"bufio"
+	"fmt"
+	"monitor_mutex/procfs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Liczba taktów zegara na sekundę w /proc (USER_HZ, w Linuksie zawsze 100)
+const procClockTicks = 100
+
+// Informacje o procesie odczytane bezpośrednio z /proc
+type procInfo struct {
+	PID        int
+	PPID       int
+	PGRP       int      // Grupa procesów (zadanie powłoki lub cała usługa)
+	Session    int      // Sesja (setsid)
+	TTY        int      // Terminal sterujący (0 = brak)
+	Comm       string   // Nazwa z /proc/<pid>/comm
+	Cmdline    []string // Argumenty z /proc/<pid>/cmdline (puste dla wątków jądra)
+	UID        int
+	User       string
+	GID        int
+	StartTicks uint64 // Czas startu w taktach od uruchomienia systemu
+	CPUTicks   uint64 // utime + stime
+	RSSKB      int64
+	Elapsed    time.Duration // Jak długo proces działa
+	CPUPercent float64       // Jak w ps: czas CPU / czas działania
+	MemPercent float64       // RSS / MemTotal
+	Cgroups    []string      // Ścieżki z /proc/<pid>/cgroup (bez korzenia "/")
+}
+
+// Pełna komenda procesu (argumenty połączone spacjami)
+func (p *procInfo) command() string {
+	return strings.Join(p.Cmdline, " ")
+}
+
+// Wspólne dane systemowe potrzebne do obliczeń
+type procSystem struct {
+	uptime     time.Duration
+	memTotalKB int64
+	pageKB     int64
+	users      map[int]string // Pamięć podręczna UID -> nazwa
+}
+
+// Odczytuje czas działania systemu i ilość pamięci
+func readProcSystem() (*procSystem, error) {
+	sys := &procSystem{
+		pageKB: int64(os.Getpagesize() / 1024),
+		users:  make(map[int]string),
+	}
+
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return nil, fmt.Errorf("nie można odczytać /proc/uptime: %v", err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("nieprawidłowy format /proc/uptime")
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("nieprawidłowy format /proc/uptime: %v", err)
+	}
+	sys.uptime = time.Duration(seconds * float64(time.Second))
+
+	if f, err := os.Open("/proc/meminfo"); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if fields := strings.Fields(scanner.Text()); len(fields) >= 2 && fields[0] == "MemTotal:" {
+				sys.memTotalKB, _ = strconv.ParseInt(fields[1], 10, 64)
+				break
+			}
+		}
+		f.Close()
+	}
+
+	return sys, nil
+}
+
+// Zamienia UID na nazwę użytkownika (z pamięcią podręczną)
+func (sys *procSystem) userName(uid int) string {
+	if name, ok := sys.users[uid]; ok {
+		return name
+	}
+	name := strconv.Itoa(uid)
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	sys.users[uid] = name
+	return name
+}
+
+// Odczytuje informacje o jednym procesie
+func readProcInfo(sys *procSystem, pid int) (*procInfo, error) {
+	dir := filepath.Join("/proc", strconv.Itoa(pid))
+
+	st, err := procfs.ReadStat(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &procInfo{
+		PID:        pid,
+		PPID:       st.PPID,
+		PGRP:       st.PGRP,
+		Session:    st.Session,
+		TTY:        st.TTY,
+		CPUTicks:   st.UTime + st.STime,
+		StartTicks: st.StartTime,
+		RSSKB:      st.RSSPages * sys.pageKB,
+	}
+
+	if comm, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+		p.Comm = strings.TrimSpace(string(comm))
+	}
+
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		for _, arg := range strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00") {
+			if arg != "" {
+				p.Cmdline = append(p.Cmdline, arg)
+			}
+		}
+	}
+
+	if status, err := os.ReadFile(filepath.Join(dir, "status")); err == nil {
+		for _, line := range strings.Split(string(status), "\n") {
+			f := strings.Fields(line)
+			if len(f) < 2 {
+				continue
+			}
+			switch f[0] {
+			case "Uid:":
+				p.UID, _ = strconv.Atoi(f[1])
+				p.User = sys.userName(p.UID)
+			case "Gid:":
+				p.GID, _ = strconv.Atoi(f[1])
+			}
+		}
+	}
+
+	p.Cgroups = readCgroups(pid)
+
+	start := time.Duration(p.StartTicks) * time.Second / procClockTicks
+	if sys.uptime > start {
+		p.Elapsed = sys.uptime - start
+	}
+	if p.Elapsed > 0 {
+		cpu := time.Duration(p.CPUTicks) * time.Second / procClockTicks
+		p.CPUPercent = float64(cpu) / float64(p.Elapsed) * 100
+	}
+	if sys.memTotalKB > 0 {
+		p.MemPercent = float64(p.RSSKB) / float64(sys.memTotalKB) * 100
+	}
+
+	return p, nil
+}
+
+// Skanuje wszystkie procesy w /proc
+func scanProcs() ([]*procInfo, error) {
+	sys, err := readProcSystem()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("nie można odczytać /proc: %v", err)
+	}
+
+	var procs []*procInfo
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		// Proces mógł się zakończyć w trakcie skanowania - pomiń
+		if p, err := readProcInfo(sys, pid); err == nil {
+			procs = append(procs, p)
+		}
+	}
+
+	return procs, nil
+}
+
+// Zwraca cele otwartych deskryptorów procesu (fd -> ścieżka lub "socket:[inode]")
+func procFDTargets(pid int) map[int]string {
+	dir := filepath.Join("/proc", strconv.Itoa(pid), "fd")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil // Brak uprawnień do cudzych procesów
+	}
+
+	targets := make(map[int]string, len(entries))
+	for _, e := range entries {
+		fd, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		if target, err := os.Readlink(filepath.Join(dir, e.Name())); err == nil {
+			targets[fd] = target
+		}
+	}
+	return targets
+}
+
+// Zwraca flagi otwarcia deskryptora z /proc/<pid>/fdinfo/<fd> (O_WRONLY, O_APPEND...)
+func procFDFlags(pid, fd int) (int, bool) {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "fdinfo", strconv.Itoa(fd)))
+	if err != nil {
+		return 0, false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "flags:"); ok {
+			flags, err := strconv.ParseInt(strings.TrimSpace(value), 8, 64)
+			return int(flags), err == nil
+		}
+	}
+	return 0, false
+}
+
+// Wyciąga numer i-węzła z celu "socket:[12345]"
+func socketInode(target string) (uint64, bool) {
+	if !strings.HasPrefix(target, "socket:[") || !strings.HasSuffix(target, "]") {
+		return 0, false
+	}
+	inode, err := strconv.ParseUint(target[len("socket:["):len(target)-1], 10, 64)
+	return inode, err == nil
+}
+
+// Zwraca nasłuchujące gniazda TCP: i-węzeł -> port (z /proc/net/tcp i tcp6)
+func listeningSockets() map[uint64]string {
+	sockets := make(map[uint64]string)
+
+	for _, path := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(f)
+		scanner.Scan() // Nagłówek
+		for scanner.Scan() {
+			// sl local_address rem_address st tx:rx tr:when retrnsmt uid timeout inode
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 10 || fields[3] != "0A" { // 0A = LISTEN
+				continue
+			}
+
+			local := fields[1]
+			colon := strings.LastIndex(local, ":")
+			if colon < 0 {
+				continue
+			}
+			port, err := strconv.ParseUint(local[colon+1:], 16, 16)
+			if err != nil {
+				continue
+			}
+			inode, err := strconv.ParseUint(fields[9], 10, 64)
+			if err != nil || inode == 0 {
+				continue
+			}
+			sockets[inode] = strconv.FormatUint(port, 10)
+		}
+		f.Close()
+	}
+
+	return sockets
+}
@@ -0,0 +1,83 @@
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"monitor_mutex/config"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Linia Procfile: "web: bundle exec puma"
+var procfileLine = regexp.MustCompile(`^([A-Za-z0-9_-]+):\s*(.+)$`)
+
+// Port pierwszego procesu i odstęp między procesami - jak w foreman
+const (
+	procfileBasePort = 5000
+	procfilePortStep = 100
+)
+
+// Przekłada Procfile na wpisy konfiguracji
+//
+// Procesy działają w katalogu Procfile ze zmiennymi z pliku .env obok niego.
+// Komenda używająca $PORT dostaje port tak jak w foreman: 5000, 5100, 5200...
+func parseProcfile(filename string, data []byte) ([]ImportedProcess, error) {
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	env, err := readDotEnv(filepath.Join(dir, ".env"))
+	if err != nil {
+		return nil, err
+	}
+
+	var imported []ImportedProcess
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m := procfileLine.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("%s: linia %d: oczekiwano nazwa: komenda", filename, n)
+		}
+
+		pc := config.ProcessConfig{Name: m[1], Command: m[2], WorkingDir: dir}
+		pc.Env = append(pc.Env, env...)
+		if strings.Contains(pc.Command, "$PORT") || strings.Contains(pc.Command, "${PORT}") {
+			pc.Env = append(pc.Env, "PORT="+strconv.Itoa(procfileBasePort+procfilePortStep*len(imported)))
+		}
+		imported = append(imported, ImportedProcess{Source: fmt.Sprintf("%s:%d", filename, n), Config: pc})
+	}
+	return imported, scanner.Err()
+}
+
+// Czyta zmienne KLUCZ=wartość z pliku .env (brak pliku = brak zmiennych)
+func readDotEnv(filename string) ([]string, error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("nie można odczytać %s: %v", filename, err)
+	}
+
+	var env []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "export "))
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || key == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env = append(env, strings.TrimSpace(key)+"="+value)
+	}
+	return env, nil
+}
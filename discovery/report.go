@@ -0,0 +1,214 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Wpis raportu: kandydat, skąd się wziął i co zdecydowało SuggestConfiguration
+type CandidateReport struct {
+	Name        string           `json:"name"`
+	PID         string           `json:"pid,omitempty"`
+	Command     string           `json:"command,omitempty"`
+	LogFile     string           `json:"log_file,omitempty"`
+	LogTargets  []LogTargetInfo  `json:"log_targets,omitempty"`
+	Port        string           `json:"port,omitempty"`
+	User        string           `json:"user,omitempty"`
+	CPUUsage    string           `json:"cpu_usage,omitempty"`
+	MemoryUsage string           `json:"memory_usage,omitempty"`
+	AgeSeconds  int64            `json:"age_seconds"`
+	Sources     []string         `json:"sources"`
+	Children    []ChildReport    `json:"children,omitempty"`
+	Unit        string           `json:"systemd_unit,omitempty"`
+	Container   string           `json:"container,omitempty"`
+	ContainerID string           `json:"container_id,omitempty"`
+	Supervisor  string           `json:"supervisor,omitempty"`
+	Accepted    bool             `json:"accepted"`
+	Check       string           `json:"check"`
+	Reason      string           `json:"reason"`
+	Profile     string           `json:"profile,omitempty"`
+	Score       int              `json:"score"`
+	ScoreItems  []ScoreInfo      `json:"score_breakdown,omitempty"`
+	Observed    *CadenceInfo     `json:"observed,omitempty"`
+	Suggested   *SuggestedConfig `json:"suggested,omitempty"`
+}
+
+// Proces potomny zwinięty do kandydata
+type ChildReport struct {
+	PID     string   `json:"pid"`
+	Name    string   `json:"name"`
+	Command string   `json:"command,omitempty"`
+	Sources []string `json:"sources"`
+}
+
+// Składnik oceny w raporcie
+type ScoreInfo struct {
+	Reason string `json:"reason"`
+	Points int    `json:"points"`
+}
+
+// Rytm logu z --observe w raporcie
+type CadenceInfo struct {
+	WindowSeconds     float64 `json:"window_seconds"`
+	Writes            int     `json:"writes"`
+	MedianGapSeconds  float64 `json:"median_gap_seconds"`
+	P95GapSeconds     float64 `json:"p95_gap_seconds"`
+	MaxSilenceSeconds float64 `json:"max_silence_seconds"`
+	Bursty            bool    `json:"bursty"`
+	Note              string  `json:"note,omitempty"`
+}
+
+// Miejsce zapisu logów w raporcie
+type LogTargetInfo struct {
+	Path        string `json:"path,omitempty"`
+	Kind        string `json:"kind"`
+	FD          int    `json:"fd"`
+	IdleSeconds int64  `json:"idle_seconds"`
+}
+
+// Parametry, które trafiłyby do konfiguracji
+type SuggestedConfig struct {
+	Name       string   `json:"name"`
+	LogFile    string   `json:"log_file"`
+	Timeout    int      `json:"timeout"`
+	Interval   int      `json:"interval"`
+	WorkingDir string   `json:"working_dir,omitempty"`
+	Env        []string `json:"env,omitempty"`
+	SecretEnv  []string `json:"skipped_secret_env,omitempty"`
+	User       string   `json:"user,omitempty"`
+	Group      string   `json:"group,omitempty"`
+	Stdout     string   `json:"stdout,omitempty"`
+	Stderr     string   `json:"stderr,omitempty"`
+	Probes     []string `json:"probes,omitempty"` // Sondy z profilu poza timeoutem logów: adaptive_timeout, watchdog
+}
+
+// Buduje raport dla listy kandydatów
+func BuildReport(candidates []ProcessCandidate, policy Policy) []CandidateReport {
+	reports := make([]CandidateReport, 0, len(candidates))
+
+	for _, c := range candidates {
+		decision := EvaluateCandidate(c, policy)
+		r := CandidateReport{
+			Name:        c.Name,
+			PID:         c.PID,
+			Command:     c.Command,
+			LogFile:     c.LogFile,
+			Port:        c.Port,
+			User:        c.User,
+			CPUUsage:    c.CPUUsage,
+			MemoryUsage: c.MemoryUsage,
+			AgeSeconds:  int64(c.Age.Seconds()),
+			Sources:     c.Sources,
+			Unit:        c.ManagedBy.Unit,
+			Container:   c.ManagedBy.Container,
+			ContainerID: c.ManagedBy.ContainerID,
+			Supervisor:  c.ManagedBy.Supervisor,
+			Accepted:    decision.Accepted,
+			Check:       decision.Check,
+			Reason:      decision.Reason,
+			Profile:     decision.Profile,
+			Score:       c.Score.Total,
+		}
+		if c.Cadence != nil {
+			r.Observed = &CadenceInfo{
+				WindowSeconds:     c.Cadence.Window.Seconds(),
+				Writes:            c.Cadence.Writes,
+				MedianGapSeconds:  c.Cadence.Median.Seconds(),
+				P95GapSeconds:     c.Cadence.P95.Seconds(),
+				MaxSilenceSeconds: c.Cadence.Max.Seconds(),
+				Bursty:            c.Cadence.Bursty,
+				Note:              c.Cadence.Note,
+			}
+		}
+		for _, item := range c.Score.Items {
+			r.ScoreItems = append(r.ScoreItems, ScoreInfo{Reason: item.Reason, Points: item.Points})
+		}
+		for _, child := range c.Children {
+			r.Children = append(r.Children, ChildReport{
+				PID:     child.PID,
+				Name:    child.Name,
+				Command: child.Command,
+				Sources: child.Sources,
+			})
+		}
+		for _, t := range c.LogTargets {
+			r.LogTargets = append(r.LogTargets, LogTargetInfo{
+				Path:        t.Path,
+				Kind:        t.Kind,
+				FD:          t.FD,
+				IdleSeconds: int64(t.Idle.Seconds()),
+			})
+		}
+		if decision.Accepted {
+			r.Suggested = &SuggestedConfig{
+				Name:       decision.Config.Name,
+				LogFile:    decision.Config.LogFile,
+				Timeout:    decision.Config.Timeout,
+				Interval:   decision.Config.Interval,
+				WorkingDir: decision.Config.WorkingDir,
+				Env:        decision.Config.Env,
+				SecretEnv:  c.Launch.SecretEnv,
+				User:       decision.Config.User,
+				Group:      decision.Config.Group,
+				Stdout:     decision.Config.Stdout,
+				Stderr:     decision.Config.Stderr,
+			}
+			if decision.Config.AdaptiveTimeout != nil {
+				r.Suggested.Probes = append(r.Suggested.Probes, "adaptive_timeout")
+			}
+			if decision.Config.Watchdog != nil {
+				r.Suggested.Probes = append(r.Suggested.Probes, "watchdog")
+			}
+		}
+		reports = append(reports, r)
+	}
+
+	return reports
+}
+
+// Wypisuje raport jako JSON
+func PrintReportJSON(w io.Writer, reports []CandidateReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reports)
+}
+
+// Wypisuje raport jako tabelę
+func PrintReportTable(w io.Writer, reports []CandidateReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NR\tOCENA\tNAZWA\tPID\tUŻYTKOWNIK\tŹRÓDŁA\tPORT\tZARZĄDCA\tDECYZJA\tPOWÓD")
+
+	for i, r := range reports {
+		decision := "odrzucony"
+		if r.Accepted {
+			decision = fmt.Sprintf("przyjęty jako %s (%s, %ds/%ds)", r.Suggested.Name, r.Profile, r.Suggested.Timeout, r.Suggested.Interval)
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			i+1, r.Score, r.Name, orDash(r.PID), orDash(r.User), strings.Join(r.Sources, ","),
+			orDash(r.Port), orDash(r.manager()), decision, r.Reason)
+	}
+
+	tw.Flush()
+}
+
+// Krótki opis zarządcy do kolumny tabeli
+func (r CandidateReport) manager() string {
+	switch {
+	case r.Container != "":
+		return r.Container + ":" + r.ContainerID
+	case r.Unit != "":
+		return r.Unit
+	}
+	return r.Supervisor
+}
+
+// Zwraca "-" dla pustych wartości w tabeli
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,311 @@
+package discovery
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"monitor_mutex/config"
+)
+
+// Wbudowane reguły - dotychczasowe listy procesów systemowych i heurystyki timeoutów
+//
+//go:embed rules_default.yaml
+var defaultRulesYAML []byte
+
+// Reguły oceny kandydatów: filtry bezpieczeństwa i profile parametrów
+type Rules struct {
+	Filters        []FilterRule  `yaml:"filters"`         // Sprawdzane po kolei, decyduje pierwszy pasujący
+	DefaultFilter  FilterRule    `yaml:"default_filter"`  // Gdy żaden filtr nie pasuje
+	Profiles       []ProfileRule `yaml:"profiles"`        // Sprawdzane po kolei, decyduje pierwszy pasujący
+	DefaultProfile ProfileRule   `yaml:"default_profile"` // Gdy żaden profil nie pasuje
+}
+
+// Warunki dopasowania - wszystkie niepuste pola muszą pasować
+type Matcher struct {
+	Name    string `yaml:"name,omitempty"`
+	Command string `yaml:"command,omitempty"`
+	Path    string `yaml:"path,omitempty"` // Pierwsze słowo komendy
+	Any     string `yaml:"any,omitempty"`  // Nazwa lub komenda
+	User    string `yaml:"user,omitempty"`
+	Cgroup  string `yaml:"cgroup,omitempty"` // Którakolwiek ścieżka cgroup
+	Port    string `yaml:"port,omitempty"`
+	LogFile string `yaml:"log_file,omitempty"`
+
+	patterns []fieldPattern
+}
+
+// Filtr: include przyjmuje kandydata, exclude go odrzuca
+type FilterRule struct {
+	Matcher `yaml:",inline"`
+	Action  string `yaml:"action"`
+	Check   string `yaml:"check,omitempty"`  // Nazwa reguły w raporcie (domyślnie "filter")
+	Reason  string `yaml:"reason,omitempty"` // Może zawierać {match}, {name}, {user}
+}
+
+// Profil parametrów konfiguracji dla pasujących procesów
+type ProfileRule struct {
+	Matcher  `yaml:",inline"`
+	Profile  string `yaml:"profile"`
+	Timeout  int    `yaml:"timeout"`
+	Interval int    `yaml:"interval"`
+
+	// Sondy życia procesu wpisywane do konfiguracji (nil = tylko stały timeout logów)
+	AdaptiveTimeout *config.AdaptiveTimeoutConfig `yaml:"adaptive_timeout,omitempty"`
+	Watchdog        *config.WatchdogConfig        `yaml:"watchdog,omitempty"` // Tylko notify - heartbeat_file zależy od procesu
+}
+
+// Akcje filtrów
+const (
+	actionInclude = "include"
+	actionExclude = "exclude"
+)
+
+// Skompilowany wzorzec jednego pola
+type fieldPattern struct {
+	field  string
+	re     *regexp.Regexp
+	negate bool
+}
+
+// Dane kandydata, do których dopasowywane są reguły
+type matchInput struct {
+	name    string
+	command string
+	path    string
+	user    string
+	port    string
+	logFile string
+	cgroups []string
+}
+
+// Wbudowane reguły sparsowane przy starcie
+var defaultRules = mustParseRules(defaultRulesYAML)
+
+// Zwraca wbudowane reguły
+func DefaultRules() *Rules {
+	return defaultRules
+}
+
+// Wypisuje wbudowane reguły z komentarzami (punkt wyjścia dla własnego pliku)
+func WriteDefaultRules(w io.Writer) error {
+	_, err := w.Write(defaultRulesYAML)
+	return err
+}
+
+func mustParseRules(data []byte) *Rules {
+	rules, err := ParseRules(data)
+	if err != nil {
+		panic(fmt.Sprintf("wbudowane reguły discovery: %v", err))
+	}
+	return rules
+}
+
+// Wczytuje plik reguł; pominięte sekcje są brane z reguł wbudowanych
+func LoadRules(filename string) (*Rules, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("nie można odczytać pliku reguł %s: %v", filename, err)
+	}
+	rules, err := ParseRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	if rules.Filters == nil {
+		rules.Filters = defaultRules.Filters
+	}
+	if rules.DefaultFilter.Action == "" {
+		rules.DefaultFilter = defaultRules.DefaultFilter
+	}
+	if rules.Profiles == nil {
+		rules.Profiles = defaultRules.Profiles
+	}
+	if rules.DefaultProfile.Profile == "" {
+		rules.DefaultProfile = defaultRules.DefaultProfile
+	}
+	return rules, nil
+}
+
+// Parsuje i sprawdza reguły (nieznane pola są błędem, żeby literówka nie wyłączyła filtra)
+func ParseRules(data []byte) (*Rules, error) {
+	var rules Rules
+	if err := yaml.UnmarshalStrict(data, &rules); err != nil {
+		return nil, fmt.Errorf("błąd parsowania reguł: %v", err)
+	}
+
+	for i := range rules.Filters {
+		if err := rules.Filters[i].compile(); err != nil {
+			return nil, fmt.Errorf("filters[%d]: %v", i, err)
+		}
+	}
+	if rules.DefaultFilter.Action != "" {
+		if err := rules.DefaultFilter.compile(); err != nil {
+			return nil, fmt.Errorf("default_filter: %v", err)
+		}
+	}
+	for i := range rules.Profiles {
+		if err := rules.Profiles[i].compile(); err != nil {
+			return nil, fmt.Errorf("profiles[%d]: %v", i, err)
+		}
+	}
+	if rules.DefaultProfile.Profile != "" {
+		if err := rules.DefaultProfile.compile(); err != nil {
+			return nil, fmt.Errorf("default_profile: %v", err)
+		}
+	}
+
+	return &rules, nil
+}
+
+func (f *FilterRule) compile() error {
+	if f.Action != actionInclude && f.Action != actionExclude {
+		return fmt.Errorf("nieznana akcja %q (dostępne: include, exclude)", f.Action)
+	}
+	if f.Check == "" {
+		f.Check = "filter"
+	}
+	return f.Matcher.compile()
+}
+
+func (p *ProfileRule) compile() error {
+	if p.Profile == "" {
+		return fmt.Errorf("brak nazwy profilu")
+	}
+	if p.Timeout <= 0 || p.Interval <= 0 {
+		return fmt.Errorf("profil %q: timeout i interval muszą być dodatnie", p.Profile)
+	}
+	if w := p.Watchdog; w != nil {
+		if w.HeartbeatFile != "" {
+			return fmt.Errorf("profil %q: watchdog.heartbeat_file zależy od procesu - ustaw go w konfiguracji", p.Profile)
+		}
+		if !w.Notify {
+			return fmt.Errorf("profil %q: watchdog w profilu wymaga notify: true", p.Profile)
+		}
+	}
+	return p.Matcher.compile()
+}
+
+// Wpisuje parametry profilu do konfiguracji; sondy ustawione już we wpisie
+// (np. watchdog z Type=notify jednostki systemd) zostają
+func (p *ProfileRule) apply(pc *config.ProcessConfig) {
+	pc.Timeout = p.Timeout
+	pc.Interval = p.Interval
+	if pc.AdaptiveTimeout == nil && p.AdaptiveTimeout != nil {
+		adaptive := *p.AdaptiveTimeout
+		pc.AdaptiveTimeout = &adaptive
+	}
+	if pc.Watchdog == nil && p.Watchdog != nil {
+		watchdog := *p.Watchdog
+		pc.Watchdog = &watchdog
+	}
+}
+
+// Kompiluje wzorce (bez rozróżniania wielkości liter, "!" na początku neguje)
+func (m *Matcher) compile() error {
+	fields := []struct{ name, pattern string }{
+		{"name", m.Name}, {"command", m.Command}, {"path", m.Path}, {"any", m.Any},
+		{"user", m.User}, {"cgroup", m.Cgroup}, {"port", m.Port}, {"log_file", m.LogFile},
+	}
+
+	m.patterns = nil
+	for _, f := range fields {
+		if f.pattern == "" {
+			continue
+		}
+		pattern, negate := strings.CutPrefix(f.pattern, "!")
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return fmt.Errorf("nieprawidłowy wzorzec %s: %v", f.name, err)
+		}
+		m.patterns = append(m.patterns, fieldPattern{field: f.name, re: re, negate: negate})
+	}
+	return nil
+}
+
+// Sprawdza dopasowanie; zwraca też pierwszy dopasowany fragment (do {match} w reason)
+func (m *Matcher) match(in matchInput) (bool, string) {
+	var matched string
+	for _, p := range m.patterns {
+		var values []string
+		switch p.field {
+		case "name":
+			values = []string{in.name}
+		case "command":
+			values = []string{in.command}
+		case "path":
+			values = []string{in.path}
+		case "any":
+			values = []string{in.name, in.command}
+		case "user":
+			values = []string{in.user}
+		case "cgroup":
+			values = in.cgroups
+		case "port":
+			values = []string{in.port}
+		case "log_file":
+			values = []string{in.logFile}
+		}
+
+		found := ""
+		ok := false
+		for _, v := range values {
+			if loc := p.re.FindStringIndex(v); loc != nil {
+				found, ok = v[loc[0]:loc[1]], true
+				break
+			}
+		}
+		if ok == p.negate {
+			return false, ""
+		}
+		if matched == "" {
+			matched = found
+		}
+	}
+	return true, matched
+}
+
+// Rozstrzyga filtry dla kandydata: pierwszy pasujący albo default_filter
+func (r *Rules) filter(in matchInput) (accepted bool, check, reason string) {
+	rule, matched := &r.DefaultFilter, ""
+	for i := range r.Filters {
+		if ok, m := r.Filters[i].match(in); ok {
+			rule, matched = &r.Filters[i], m
+			break
+		}
+	}
+
+	reason = strings.NewReplacer("{match}", matched, "{name}", in.name, "{user}", in.user).Replace(rule.Reason)
+	return rule.Action == actionInclude, rule.Check, reason
+}
+
+// Wybiera profil parametrów: pierwszy pasujący albo default_profile
+func (r *Rules) profile(in matchInput) *ProfileRule {
+	for i := range r.Profiles {
+		if ok, _ := r.Profiles[i].match(in); ok {
+			return &r.Profiles[i]
+		}
+	}
+	return &r.DefaultProfile
+}
+
+// Dane kandydata do dopasowania reguł
+func candidateInput(candidate ProcessCandidate) matchInput {
+	in := matchInput{
+		name:    candidate.Name,
+		command: candidate.Command,
+		user:    candidate.User,
+		port:    candidate.Port,
+		logFile: candidate.LogFile,
+		cgroups: candidate.Cgroups,
+	}
+	if fields := strings.Fields(candidate.Command); len(fields) > 0 {
+		in.path = fields[0]
+	}
+	return in
+}
@@ -0,0 +1,169 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Wbudowane reguły dają te same decyzje co dawne listy i heurystyki w kodzie
+func TestDefaultRulesDecisions(t *testing.T) {
+	tests := []struct {
+		candidate ProcessCandidate
+		accepted  bool
+		check     string
+		reason    string
+		profile   string
+		timeout   int
+		interval  int
+	}{
+		{
+			candidate: ProcessCandidate{Name: "sshd", Command: "sshd: /usr/sbin/sshd -D", User: "root"},
+			check:     "system", reason: `nazwa lub komenda zawiera "ssh"`,
+		},
+		{
+			candidate: ProcessCandidate{Name: "nginx", Command: "/usr/sbin/nginx -g daemon off;", User: "root"},
+			check:     "system", reason: "komenda w katalogu systemowym /usr/sbin/",
+		},
+		{
+			candidate: ProcessCandidate{Name: "kworker/0:1", Command: "kworker", User: "root"},
+			check:     "system", reason: "proces jądra lub init",
+		},
+		{
+			candidate: ProcessCandidate{Name: "app", Command: "/opt/app/app --login", User: "alice"},
+			check:     "safety", reason: `może być krytyczny dla systemu (zawiera "login")`,
+		},
+		{
+			candidate: ProcessCandidate{Name: "app", Command: "/srv/app/app", User: "root"},
+			check:     "safety", reason: "proces root spoza bezpiecznych lokalizacji",
+		},
+		{
+			candidate: ProcessCandidate{Name: "app", Command: "/srv/app/app", User: "alice"},
+			accepted:  true, check: "safety", reason: "proces użytkownika alice",
+			profile: "test w /tmp", timeout: 30, interval: 5,
+		},
+		{
+			candidate: ProcessCandidate{Name: "java", Command: "/opt/app/bin/java -jar app.jar", User: "root", LogFile: "/var/log/app.log"},
+			accepted:  true, check: "safety", reason: "komenda w bezpiecznej lokalizacji",
+			profile: "aplikacja Java", timeout: 300, interval: 20,
+		},
+		{
+			candidate: ProcessCandidate{Name: "api", Command: "/opt/api/api", User: "root", Port: "8080", LogFile: "/var/log/api.log"},
+			accepted:  true, check: "safety", reason: "komenda w bezpiecznej lokalizacji",
+			profile: "serwer sieciowy", timeout: 90, interval: 10,
+		},
+		{
+			candidate: ProcessCandidate{Name: "worker", Command: "/opt/jobs/run", User: "root", LogFile: "/var/log/jobs.log"},
+			check:     "system", reason: "proces jądra lub init",
+		},
+		{
+			candidate: ProcessCandidate{Name: "jobs", Command: "/opt/jobs/run", User: "root", LogFile: "/var/log/jobs.log"},
+			accepted:  true, check: "safety", reason: "komenda w bezpiecznej lokalizacji",
+			profile: "domyślny", timeout: 60, interval: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		d := EvaluateCandidate(tt.candidate, Policy{})
+		if d.Accepted != tt.accepted || d.Check != tt.check || d.Reason != tt.reason {
+			t.Errorf("%s (%s): decyzja %v/%s/%q, oczekiwano %v/%s/%q",
+				tt.candidate.Name, tt.candidate.Command, d.Accepted, d.Check, d.Reason, tt.accepted, tt.check, tt.reason)
+			continue
+		}
+		if tt.accepted && (d.Profile != tt.profile || d.Config.Timeout != tt.timeout || d.Config.Interval != tt.interval) {
+			t.Errorf("%s: profil %q %d/%d, oczekiwano %q %d/%d",
+				tt.candidate.Name, d.Profile, d.Config.Timeout, d.Config.Interval, tt.profile, tt.timeout, tt.interval)
+		}
+	}
+}
+
+// Plik reguł zastępuje tylko sekcje, które zawiera
+func TestLoadRules(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.yaml")
+	data := `
+filters:
+  - action: exclude
+    check: cgroup
+    cgroup: 'docker'
+    reason: 'w kontenerze'
+  - action: include
+    user: '!^root$'
+    reason: 'użytkownik {user}'
+profiles:
+  - profile: kolejka
+    name: '^queue'
+    timeout: 600
+    interval: 30
+    adaptive_timeout:
+      percentile: 95
+  - profile: notify
+    path: '^/srv/notify'
+    timeout: 120
+    interval: 10
+    watchdog:
+      notify: true
+      timeout: 30
+`
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadRules(file)
+	if err != nil {
+		t.Fatalf("LoadRules: %v", err)
+	}
+	policy := Policy{Rules: rules}
+
+	d := EvaluateCandidate(ProcessCandidate{Name: "queue-worker", Command: "/srv/q", User: "bob"}, policy)
+	if !d.Accepted || d.Check != "filter" || d.Reason != "użytkownik bob" || d.Profile != "kolejka" || d.Config.Timeout != 600 {
+		t.Errorf("nieoczekiwana decyzja: %+v", d)
+	}
+	if a := d.Config.AdaptiveTimeout; a == nil || a.Percentile != 95 || d.Config.Watchdog != nil {
+		t.Errorf("sondy profilu kolejka: %+v %+v", a, d.Config.Watchdog)
+	}
+	// Każdy wpis dostaje własną kopię sond z profilu
+	if a := EvaluateCandidate(ProcessCandidate{Name: "queue-2", Command: "/srv/q2", User: "bob"}, policy).Config.AdaptiveTimeout; a == d.Config.AdaptiveTimeout {
+		t.Error("wpisy współdzielą adaptive_timeout z profilu")
+	}
+
+	d = EvaluateCandidate(ProcessCandidate{Name: "api", Command: "/srv/notify/api", User: "bob"}, policy)
+	if w := d.Config.Watchdog; d.Profile != "notify" || w == nil || !w.Notify || w.Timeout != 30 || d.Config.AdaptiveTimeout != nil {
+		t.Errorf("sondy profilu notify: %+v %+v", d, w)
+	}
+	if r := BuildReport([]ProcessCandidate{{Name: "api", Command: "/srv/notify/api", User: "bob"}}, policy); len(r) != 1 ||
+		r[0].Suggested == nil || strings.Join(r[0].Suggested.Probes, ",") != "watchdog" {
+		t.Errorf("raport bez sondy watchdog: %+v", r)
+	}
+
+	d = EvaluateCandidate(ProcessCandidate{Name: "web", Command: "/srv/web", User: "bob", Cgroups: []string{"/docker/abc"}}, policy)
+	if d.Accepted || d.Check != "cgroup" || d.Reason != "w kontenerze" {
+		t.Errorf("oczekiwano odrzucenia przez regułę cgroup: %+v", d)
+	}
+
+	// Brak default_filter w pliku - obowiązuje wbudowany
+	d = EvaluateCandidate(ProcessCandidate{Name: "web", Command: "/srv/web", User: "root"}, policy)
+	if d.Accepted || d.Reason != "proces root spoza bezpiecznych lokalizacji" {
+		t.Errorf("oczekiwano wbudowanego default_filter: %+v", d)
+	}
+}
+
+// Błędy w pliku reguł są zgłaszane, a nie ignorowane
+func TestParseRulesErrors(t *testing.T) {
+	tests := map[string]string{
+		"filters:\n  - action: skip\n":                                                                                                   "nieznana akcja",
+		"filters:\n  - action: exclude\n    nmae: x\n":                                                                                   "nmae",
+		"filters:\n  - action: exclude\n    name: '('\n":                                                                                 "nieprawidłowy wzorzec name",
+		"profiles:\n  - profile: x\n    timeout: 0\n    interval: 5\n":                                                                   "muszą być dodatnie",
+		"profiles:\n  - profile: x\n    timeout: 60\n    interval: 5\n    watchdog:\n      timeout: 30\n":                                "wymaga notify",
+		"profiles:\n  - profile: x\n    timeout: 60\n    interval: 5\n    watchdog:\n      notify: true\n      heartbeat_file: /run/x\n": "heartbeat_file",
+		"profiles:\n  - profile: x\n    timeout: 60\n    interval: 5\n    adaptive_timeout:\n      percentil: 99\n":                      "percentil",
+	}
+
+	for data, want := range tests {
+		_, err := ParseRules([]byte(data))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseRules(%q) = %v, oczekiwano błędu z %q", data, err, want)
+		}
+	}
+}
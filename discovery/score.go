@@ -0,0 +1,100 @@
+package discovery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Składnik oceny: za co przyznano (lub odjęto) punkty
+type ScoreItem struct {
+	Reason string
+	Points int
+}
+
+// Ocena "czy warto pilnować" kandydata razem z rozbiciem na składniki
+type Score struct {
+	Total int
+	Items []ScoreItem
+}
+
+// Rozbicie oceny do listy kandydatów: "+25 port 8080, +20 użytkownik app"
+func (s Score) String() string {
+	parts := make([]string, len(s.Items))
+	for i, item := range s.Items {
+		parts[i] = fmt.Sprintf("%+d %s", item.Points, item.Reason)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (s *Score) add(points int, format string, args ...interface{}) {
+	s.Total += points
+	s.Items = append(s.Items, ScoreItem{Reason: fmt.Sprintf(format, args...), Points: points})
+}
+
+// Punkty za poszczególne cechy kandydata
+const (
+	scoreUserOwned   = 20  // Proces użytkownika, nie systemu
+	scorePort        = 25  // Nasłuchuje - to usługa, ktoś z niej korzysta
+	scoreLogFresh    = 25  // Log zapisany w ostatniej minucie - monitor ma co obserwować
+	scoreLogRecent   = 15  // Log zapisany w ostatniej godzinie
+	scoreLogStale    = 5   // Log jest, ale dawno nieużywany
+	scoreUptimeDay   = 15  // Działa ponad dobę
+	scoreUptimeHour  = 10  // Działa ponad godzinę
+	scoreManaged     = -30 // Ktoś już go pilnuje - podwójny restart
+	scoreRestartRisk = -50 // Reguły odrzucają restart (proces systemowy, niebezpieczny)
+)
+
+// Ocenia, na ile warto objąć kandydata nadzorem
+func ScoreCandidate(candidate ProcessCandidate, policy Policy) Score {
+	var s Score
+
+	if candidate.User != "" && candidate.User != "root" && candidate.User != "system" {
+		s.add(scoreUserOwned, "użytkownik %s", candidate.User)
+	}
+	if candidate.Port != "" {
+		s.add(scorePort, "port %s", candidate.Port)
+	}
+
+	if len(candidate.LogTargets) > 0 {
+		idle := candidate.LogTargets[0].Idle
+		switch {
+		case idle < time.Minute:
+			s.add(scoreLogFresh, "aktywny log")
+		case idle < time.Hour:
+			s.add(scoreLogRecent, "log sprzed %s", formatIdle(idle))
+		default:
+			s.add(scoreLogStale, "nieaktywny log")
+		}
+	}
+
+	switch {
+	case candidate.Age >= 24*time.Hour:
+		s.add(scoreUptimeDay, "działa %s", formatIdle(candidate.Age))
+	case candidate.Age >= time.Hour:
+		s.add(scoreUptimeHour, "działa %s", formatIdle(candidate.Age))
+	}
+
+	if candidate.ManagedBy.Managed() {
+		s.add(scoreManaged, "zarządzany przez %s", candidate.ManagedBy)
+	}
+
+	// Bezpieczeństwo restartu według reguł - niezależnie od tego, czy ktoś już zarządza procesem
+	policy.IncludeManaged = true
+	if decision := EvaluateCandidate(candidate, policy); !decision.Accepted {
+		s.add(scoreRestartRisk, "odrzucony: %s", decision.Reason)
+	}
+
+	return s
+}
+
+// Ocenia kandydatów i układa ich od najbardziej wartych nadzoru (przy remisie - kolejność wykrycia)
+func RankCandidates(candidates []ProcessCandidate, policy Policy) {
+	for i := range candidates {
+		candidates[i].Score = ScoreCandidate(candidates[i], policy)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score.Total > candidates[j].Score.Total
+	})
+}
@@ -0,0 +1,53 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+)
+
+// Składniki oceny i ich suma
+func TestScoreCandidate(t *testing.T) {
+	candidate := ProcessCandidate{
+		Name:       "python3",
+		Command:    "python3 /home/app/api.py",
+		User:       "app",
+		Port:       "8000",
+		LogTargets: []LogTarget{{Path: "/home/app/api.log", Kind: logKindFile, Idle: 5 * time.Second}},
+		Age:        48 * time.Hour,
+	}
+
+	s := ScoreCandidate(candidate, Policy{})
+	want := scoreUserOwned + scorePort + scoreLogFresh + scoreUptimeDay
+	if s.Total != want || len(s.Items) != 4 {
+		t.Errorf("ocena = %d (%s), oczekiwano %d z 4 składników", s.Total, s, want)
+	}
+
+	// Zarządzany i odrzucany przez reguły traci punkty
+	candidate.ManagedBy = Management{Unit: "api.service"}
+	candidate.User = "root"
+	candidate.Command = "/usr/sbin/api"
+	s = ScoreCandidate(candidate, Policy{})
+	want = scorePort + scoreLogFresh + scoreUptimeDay + scoreManaged + scoreRestartRisk
+	if s.Total != want {
+		t.Errorf("ocena = %d (%s), oczekiwano %d", s.Total, s, want)
+	}
+}
+
+// Najwyżej oceniani na początku, przy remisie kolejność wykrycia
+func TestRankCandidates(t *testing.T) {
+	candidates := []ProcessCandidate{
+		{Name: "a", PID: "1", Command: "/usr/sbin/a", User: "root"},
+		{Name: "b", PID: "2", Command: "/home/app/b", User: "app"},
+		{Name: "c", PID: "3", Command: "/home/app/c", User: "app", Port: "9000"},
+		{Name: "d", PID: "4", Command: "/home/app/d", User: "app"},
+	}
+	RankCandidates(candidates, Policy{})
+
+	var order string
+	for _, c := range candidates {
+		order += c.Name
+	}
+	if order != "cbda" {
+		t.Errorf("kolejność = %s, oczekiwano cbda", order)
+	}
+}
@@ -0,0 +1,85 @@
+package discovery
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Kryterium nieinteraktywnego wyboru kandydatów (--select)
+type Selector struct {
+	kind  string         // all, name, user, port
+	value string         // Wartość dla user i port
+	re    *regexp.Regexp // Wyrażenie dla name
+}
+
+// Parsuje kryterium w postaci all | name=regex | user=nazwa | port=numer
+func ParseSelector(spec string) (Selector, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "all" {
+		return Selector{kind: "all"}, nil
+	}
+
+	kind, value, ok := strings.Cut(spec, "=")
+	if !ok || value == "" {
+		return Selector{}, fmt.Errorf("nieprawidłowe kryterium %q (oczekiwano all, name=regex, user=nazwa lub port=numer)", spec)
+	}
+
+	switch kind {
+	case "name":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return Selector{}, fmt.Errorf("nieprawidłowe wyrażenie w %q: %v", spec, err)
+		}
+		return Selector{kind: kind, re: re}, nil
+	case "user", "port":
+		return Selector{kind: kind, value: value}, nil
+	default:
+		return Selector{}, fmt.Errorf("nieznany rodzaj kryterium %q w %q", kind, spec)
+	}
+}
+
+// Sprawdza czy kandydat spełnia kryterium
+func (s Selector) matches(c ProcessCandidate) bool {
+	switch s.kind {
+	case "all":
+		return true
+	case "name":
+		return s.re.MatchString(c.Name) || s.re.MatchString(c.ConfigName)
+	case "user":
+		return c.User == s.value
+	case "port":
+		return c.Port == s.value
+	}
+	return false
+}
+
+// Lista kryteriów --select (flaga może wystąpić wielokrotnie)
+type SelectorList []Selector
+
+func (l *SelectorList) String() string {
+	return fmt.Sprintf("%d kryteriów", len(*l))
+}
+
+func (l *SelectorList) Set(spec string) error {
+	s, err := ParseSelector(spec)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, s)
+	return nil
+}
+
+// Wybiera kandydatów spełniających którekolwiek z kryteriów
+func (l SelectorList) Filter(candidates []ProcessCandidate) []ProcessCandidate {
+	var selected []ProcessCandidate
+	for _, c := range candidates {
+		for _, s := range l {
+			if s.matches(c) {
+				selected = append(selected, c)
+				break
+			}
+		}
+	}
+	return selected
+}
@@ -0,0 +1,248 @@
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"monitor_mutex/config"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Sekcja pliku INI supervisord z wpisami w kolejności z pliku
+type iniSection struct {
+	name   string
+	keys   []string
+	values map[string]string
+	line   int
+}
+
+// Czyta plik INI w składni supervisord: komentarze ; i #, wcięte linie kontynuują wartość
+func parseINI(data []byte) ([]*iniSection, error) {
+	var sections []*iniSection
+	var current *iniSection
+	lastKey := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		raw := strings.TrimRight(scanner.Text(), "\r")
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = &iniSection{name: strings.TrimSpace(line[1 : len(line)-1]), values: map[string]string{}, line: n}
+			sections = append(sections, current)
+			lastKey = ""
+			continue
+		}
+		if current == nil {
+			return nil, fmt.Errorf("linia %d: wpis przed pierwszą sekcją", n)
+		}
+
+		// Wcięta linia dopisuje się do poprzedniej wartości (np. długie environment)
+		if lastKey != "" && unicode.IsSpace(rune(raw[0])) {
+			current.values[lastKey] += "\n" + stripInlineComment(line)
+			continue
+		}
+
+		sep := strings.IndexAny(line, "=:")
+		if sep <= 0 {
+			return nil, fmt.Errorf("linia %d: oczekiwano klucz=wartość", n)
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:sep]))
+		if _, seen := current.values[key]; !seen {
+			current.keys = append(current.keys, key)
+		}
+		current.values[key] = stripInlineComment(strings.TrimSpace(line[sep+1:]))
+		lastKey = key
+	}
+	return sections, scanner.Err()
+}
+
+// Usuwa komentarz " ;" z końca wartości
+func stripInlineComment(value string) string {
+	for i := 1; i < len(value); i++ {
+		if value[i] == ';' && (value[i-1] == ' ' || value[i-1] == '\t') {
+			return strings.TrimSpace(value[:i])
+		}
+	}
+	return value
+}
+
+// Wyrażenia %(nazwa)s w wartościach supervisord
+var supervisordExpansion = regexp.MustCompile(`%\((\w+)\)(\d*)[sd]`)
+
+// Przekłada sekcje [program:x] na wpisy konfiguracji; [include] jest czytany rekurencyjnie
+func parseSupervisord(filename string, data []byte, seen map[string]bool) ([]ImportedProcess, error) {
+	if abs, err := filepath.Abs(filename); err == nil {
+		if seen[abs] {
+			return nil, nil // Cykl dołączeń
+		}
+		seen[abs] = true
+	}
+
+	sections, err := parseINI(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	here := filepath.Dir(filename)
+	var imported, included []ImportedProcess
+	for _, section := range sections {
+		if section.name == "include" {
+			// Dołączone programy trafiają za programy z tego pliku
+			entries, err := supervisordInclude(here, section.values["files"], seen)
+			if err != nil {
+				return nil, fmt.Errorf("%s: [include]: %v", filename, err)
+			}
+			included = append(included, entries...)
+			continue
+		}
+
+		name, ok := strings.CutPrefix(section.name, "program:")
+		if !ok {
+			continue // Ustawienia samego supervisord, grupy, serwer RPC
+		}
+		p, err := supervisordProgram(name, section, here)
+		if err != nil {
+			return nil, fmt.Errorf("%s: [%s]: %v", filename, section.name, err)
+		}
+		p.Source = fmt.Sprintf("%s:%d [%s]", filename, section.line, section.name)
+		imported = append(imported, p)
+	}
+	return append(imported, included...), nil
+}
+
+// Czyta pliki z files= sekcji [include] (wzorce względem katalogu pliku)
+func supervisordInclude(here, files string, seen map[string]bool) ([]ImportedProcess, error) {
+	var imported []ImportedProcess
+	for _, pattern := range strings.Fields(files) {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(here, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			data, err := os.ReadFile(match)
+			if err != nil {
+				return nil, fmt.Errorf("nie można odczytać %s: %v", match, err)
+			}
+			included, err := parseSupervisord(match, data, seen)
+			if err != nil {
+				return nil, err
+			}
+			imported = append(imported, included...)
+		}
+	}
+	return imported, nil
+}
+
+// Przekłada jedną sekcję [program:x]
+func supervisordProgram(name string, section *iniSection, here string) (ImportedProcess, error) {
+	p := ImportedProcess{Config: config.ProcessConfig{Name: name}}
+	pc := &p.Config
+
+	expand := func(key, value string) string {
+		return supervisordExpansion.ReplaceAllStringFunc(value, func(m string) string {
+			match := supervisordExpansion.FindStringSubmatch(m)
+			variable := match[1]
+			switch {
+			case variable == "program_name" || variable == "group_name":
+				return name
+			case variable == "here":
+				return here
+			case variable == "process_num":
+				return fmt.Sprintf("%"+match[2]+"d", 0) // %(process_num)02d -> 00
+			case variable == "host_node_name":
+				host, _ := os.Hostname()
+				return host
+			case strings.HasPrefix(variable, "ENV_"):
+				return os.Getenv(strings.TrimPrefix(variable, "ENV_"))
+			}
+			p.Unmapped = append(p.Unmapped, fmt.Sprintf("%s: nieznane wyrażenie %s", key, m))
+			return m
+		})
+	}
+
+	redirectStderr := false
+	for _, key := range section.keys {
+		value := expand(key, section.values[key])
+		if strings.EqualFold(value, "true") || strings.EqualFold(value, "false") {
+			value = strings.ToLower(value)
+		}
+		switch key {
+		case "command":
+			pc.Command = strings.ReplaceAll(value, "\n", " ") // Linie kontynuacji to dalsze argumenty
+		case "directory":
+			pc.WorkingDir = value
+		case "user":
+			pc.User = value
+		case "environment":
+			for _, entry := range splitQuoted(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+				pc.Env = append(pc.Env, strings.TrimSpace(entry))
+			}
+		case "stdout_logfile":
+			pc.Stdout = supervisordLogFile(value)
+		case "stderr_logfile":
+			pc.Stderr = supervisordLogFile(value)
+		case "redirect_stderr":
+			redirectStderr = value == "true"
+		case "autorestart":
+			switch value {
+			case "true":
+			case "unexpected":
+				p.Unmapped = append(p.Unmapped, "autorestart=unexpected: monitor restartuje proces po każdym zakończeniu, niezależnie od kodu wyjścia")
+			default:
+				p.Unmapped = append(p.Unmapped, fmt.Sprintf("autorestart=%s: monitor zawsze restartuje zakończony proces", value))
+			}
+		case "startsecs":
+			p.Unmapped = append(p.Unmapped, fmt.Sprintf("startsecs=%s: monitor uznaje proces za uruchomiony od razu, a licznik prób zeruje po 10 spokojnych sprawdzeniach", value))
+		case "startretries":
+			if value != "3" {
+				p.Unmapped = append(p.Unmapped, fmt.Sprintf("startretries=%s: monitor ma stałe 3 próby restartu", value))
+			}
+		case "stopsignal":
+			if sig := strings.TrimPrefix(strings.ToUpper(value), "SIG"); sig != "TERM" {
+				p.Unmapped = append(p.Unmapped, fmt.Sprintf("stopsignal=%s: monitor zatrzymuje proces sygnałem SIGTERM", value))
+			}
+		case "stopwaitsecs":
+			if value != "5" {
+				p.Unmapped = append(p.Unmapped, fmt.Sprintf("stopwaitsecs=%s: monitor czeka 5 s przed SIGKILL", value))
+			}
+		case "autostart":
+			if value != "true" {
+				p.Unmapped = append(p.Unmapped, fmt.Sprintf("autostart=%s: monitor uruchamia proces od razu (po starcie można go zatrzymać poleceniem stop)", value))
+			}
+		case "numprocs":
+			if value != "1" {
+				p.Unmapped = append(p.Unmapped, fmt.Sprintf("numprocs=%s: zaimportowano jedną kopię", value))
+			}
+		default:
+			p.Unmapped = append(p.Unmapped, fmt.Sprintf("%s=%s: brak odpowiednika", key, value))
+		}
+	}
+
+	if pc.Command == "" {
+		return p, fmt.Errorf("brak command")
+	}
+	if redirectStderr {
+		pc.Stderr = pc.Stdout
+	}
+	return p, nil
+}
+
+// Plik logu supervisord; AUTO, NONE, syslog i wyjście supervisord nie są plikami do obserwacji
+func supervisordLogFile(value string) string {
+	switch strings.ToUpper(value) {
+	case "AUTO", "NONE", "SYSLOG", "/DEV/STDOUT", "/DEV/STDERR", "":
+		return ""
+	}
+	return value
+}
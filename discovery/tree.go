@@ -0,0 +1,186 @@
+package discovery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Proces potomny wchłonięty przez kandydata będącego korzeniem usługi
+type ChildProcess struct {
+	PID     string
+	Name    string
+	Command string
+	Sources []string // Dlaczego sam byłby kandydatem
+
+	candidate ProcessCandidate // Pełny wpis dziecka - do wyboru go jako korzenia
+}
+
+// Opis do listy kandydatów
+func (c ChildProcess) String() string {
+	return fmt.Sprintf("%s (PID %s)", c.Name, c.PID)
+}
+
+// Procesy, powyżej których nie szukamy korzenia usługi: init, sesje, terminale, nadzorcy
+var treeBoundaries = []string{
+	"init", "systemd", "sshd", "login", "su", "sudo", "cron", "crond", "atd",
+	"tmux: server", "tmux", "screen", "SCREEN", "containerd-shim", "conmon", "tini", "dumb-init",
+}
+
+// Sprawdza czy proces jest granicą drzewa usługi
+func isTreeBoundary(p *procInfo) bool {
+	if p.PID <= 1 || containsString(treeBoundaries, p.Comm) || isSupervisorName(p.Comm) {
+		return true
+	}
+	if strings.HasPrefix(p.Comm, "containerd-shim") || strings.HasSuffix(p.Comm, "term") {
+		return true // Emulatory terminala (xterm, gnome-terminal...) i shimy kontenerów
+	}
+	// Lider sesji z terminalem to powłoka logowania - uruchomione z niej programy to osobne usługi
+	return p.PID == p.Session && p.TTY != 0
+}
+
+// Znajduje korzeń usługi: najwyższego przodka z tej samej grupy procesów
+//
+// Powłoka z kontrolą zadań daje każdemu zadaniu (app.sh &) własną grupę, a skrypt
+// i jego dzieci (sleep, python) dzielą grupę z liderem. Procesy uruchomione bez
+// kontroli zadań (cron, inny program) dziedziczą grupę rodzica, dlatego wspinaczkę
+// zatrzymują też granice z treeBoundaries.
+func serviceRoot(p *procInfo, byPID map[int]*procInfo) *procInfo {
+	root := p
+	seen := map[int]bool{p.PID: true}
+	for {
+		parent, ok := byPID[root.PPID]
+		if !ok || seen[parent.PID] || parent.PGRP != p.PGRP || isTreeBoundary(parent) || len(parent.Cmdline) == 0 {
+			return root
+		}
+		seen[parent.PID] = true
+		root = parent
+	}
+}
+
+// Zwija kandydatów z jednego drzewa procesów w kandydata-korzeń
+//
+// Zamiast osobnych wpisów dla bash app.sh, sleep 8 i python worker.py powstaje jeden
+// wpis app.sh z dziećmi; log i port dzieci przechodzą na korzeń, bo restart korzenia
+// restartuje całe drzewo.
+func collapseTree(candidates []ProcessCandidate, procs []*procInfo) []ProcessCandidate {
+	byPID := make(map[int]*procInfo, len(procs))
+	for _, p := range procs {
+		byPID[p.PID] = p
+	}
+
+	var roots []ProcessCandidate
+	index := make(map[int]int) // PID korzenia -> indeks w roots
+	var members [][]ProcessCandidate
+
+	for _, candidate := range candidates {
+		pid, err := strconv.Atoi(candidate.PID)
+		p, ok := byPID[pid]
+		if err != nil || !ok {
+			roots = append(roots, candidate)
+			members = append(members, nil)
+			continue
+		}
+
+		root := serviceRoot(p, byPID)
+		i, ok := index[root.PID]
+		if !ok {
+			i = len(roots)
+			index[root.PID] = i
+			roots = append(roots, ProcessCandidate{
+				Name:    root.Comm,
+				PID:     strconv.Itoa(root.PID),
+				User:    root.User,
+				Command: root.command(),
+				Age:     root.Elapsed,
+			})
+			members = append(members, nil)
+		}
+		members[i] = append(members[i], candidate)
+	}
+
+	for i := range roots {
+		// Najpierw wpisy samego korzenia - jego log i port mają pierwszeństwo przed dziećmi
+		var own, children []ProcessCandidate
+		for _, m := range members[i] {
+			if m.PID == roots[i].PID {
+				own = append(own, m)
+			} else {
+				children = append(children, m)
+			}
+		}
+		for _, m := range own {
+			mergeCandidate(&roots[i], m)
+		}
+		for _, m := range children {
+			mergeCandidate(&roots[i], m)
+			addChild(&roots[i], m)
+		}
+	}
+
+	return roots
+}
+
+// Wybiera n-te dziecko (od 1) jako korzeń nadzoru zamiast wykrytego przodka
+//
+// Przydaje się, gdy przodek to tylko skrypt startowy, a usługą jest jeden z procesów
+// potomnych. Zarządca jest dziedziczony po korzeniu, pozostałe dzieci są pomijane.
+func (c ProcessCandidate) ChildRoot(n int) (ProcessCandidate, bool) {
+	if n < 1 || n > len(c.Children) {
+		return ProcessCandidate{}, false
+	}
+	child := c.Children[n-1].candidate
+	child.ManagedBy = c.ManagedBy
+	child.Cgroups = c.Cgroups
+	child.Children = nil
+	child.ConfigName = serviceName(child)
+	return child, true
+}
+
+// Przenosi na korzeń metody wykrycia, log, port, użytkownika i zużycie zasobów kandydata
+func mergeCandidate(root *ProcessCandidate, c ProcessCandidate) {
+	for _, source := range c.Sources {
+		if !containsString(root.Sources, source) {
+			root.Sources = append(root.Sources, source)
+		}
+	}
+	if root.Port == "" {
+		root.Port = c.Port
+	}
+	if len(root.LogTargets) == 0 && len(c.LogTargets) > 0 {
+		root.LogFile = c.LogFile
+		root.LogTargets = c.LogTargets
+	}
+	if c.PID != root.PID {
+		return // Użytkownik, wiek i zużycie zasobów dziecka nie opisują korzenia
+	}
+	if root.User == "" {
+		root.User = c.User
+	}
+	if root.Age == 0 {
+		root.Age = c.Age
+	}
+	if c.CPUUsage != "" {
+		root.CPUUsage = c.CPUUsage
+		root.MemoryUsage = c.MemoryUsage
+	}
+}
+
+// Dopisuje dziecko do korzenia (jeden wpis na PID)
+func addChild(root *ProcessCandidate, c ProcessCandidate) {
+	for i, child := range root.Children {
+		if child.PID == c.PID {
+			mergeCandidate(&root.Children[i].candidate, c)
+			root.Children[i].Sources = root.Children[i].candidate.Sources
+			return
+		}
+	}
+	c.Sources = append([]string(nil), c.Sources...)
+	root.Children = append(root.Children, ChildProcess{
+		PID:       c.PID,
+		Name:      c.Name,
+		Command:   c.Command,
+		Sources:   c.Sources,
+		candidate: c,
+	})
+}
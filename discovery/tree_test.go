@@ -0,0 +1,66 @@
+package discovery
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Skrypt z dziećmi staje się jednym kandydatem; osobne zadania powłoki zostają osobno
+func TestCollapseTree(t *testing.T) {
+	procs := []*procInfo{
+		{PID: 1, Comm: "systemd", Cmdline: []string{"/sbin/init"}},
+		{PID: 100, PPID: 1, PGRP: 100, Session: 100, TTY: 0, Comm: "sshd", Cmdline: []string{"sshd"}},
+		// Powłoka logowania i dwa zadania uruchomione z niej w tle
+		{PID: 200, PPID: 100, PGRP: 200, Session: 200, TTY: 34817, Comm: "bash", Cmdline: []string{"-bash"}},
+		{PID: 300, PPID: 200, PGRP: 300, Session: 200, Comm: "app.sh", Cmdline: []string{"/bin/bash", "/srv/app.sh"}, User: "app"},
+		{PID: 301, PPID: 300, PGRP: 300, Session: 200, Comm: "sleep", Cmdline: []string{"sleep", "8"}},
+		{PID: 302, PPID: 300, PGRP: 300, Session: 200, Comm: "python3", Cmdline: []string{"python3", "worker.py"}},
+		{PID: 400, PPID: 200, PGRP: 400, Session: 200, Comm: "redis-server", Cmdline: []string{"redis-server"}},
+		// Zadanie crona: grupa dziedziczona po cronie, granicą jest cron
+		{PID: 500, PPID: 1, PGRP: 500, Session: 500, Comm: "cron", Cmdline: []string{"cron"}},
+		{PID: 501, PPID: 500, PGRP: 500, Session: 500, Comm: "sh", Cmdline: []string{"sh", "-c", "/opt/job.sh"}},
+		{PID: 502, PPID: 501, PGRP: 500, Session: 500, Comm: "job.sh", Cmdline: []string{"/bin/sh", "/opt/job.sh"}},
+	}
+
+	candidates := []ProcessCandidate{
+		{Name: "sleep", PID: "301", Command: "sleep 8", LogFile: "/srv/app.log", LogTargets: []LogTarget{{Path: "/srv/app.log", Kind: logKindStdout, FD: 1}}, Sources: []string{sourceLog}},
+		{Name: "python3", PID: "302", Command: "python3 worker.py", Port: "8000", Sources: []string{sourcePort}},
+		{Name: "app.sh", PID: "300", Command: "/bin/bash /srv/app.sh", CPUUsage: "0.1", Sources: []string{sourceLongRunning}},
+		{Name: "redis-server", PID: "400", Command: "redis-server", Port: "6379", Sources: []string{sourcePort}},
+		{Name: "job.sh", PID: "502", Command: "/bin/sh /opt/job.sh", Sources: []string{sourceLongRunning}},
+	}
+
+	got := collapseTree(candidates, procs)
+	if len(got) != 3 {
+		t.Fatalf("oczekiwano 3 kandydatów, jest %d: %+v", len(got), got)
+	}
+
+	app := got[0]
+	if app.PID != "300" || app.Name != "app.sh" || app.User != "app" || app.Command != "/bin/bash /srv/app.sh" {
+		t.Errorf("korzeń skryptu: %+v", app)
+	}
+	if app.LogFile != "/srv/app.log" || app.Port != "8000" || app.CPUUsage != "0.1" {
+		t.Errorf("log, port i CPU dzieci powinny przejść na korzeń: %+v", app)
+	}
+	if want := []string{sourceLongRunning, sourceLog, sourcePort}; !reflect.DeepEqual(app.Sources, want) {
+		t.Errorf("Sources = %v, oczekiwano %v", app.Sources, want)
+	}
+	if len(app.Children) != 2 || app.Children[0].PID != "301" || app.Children[1].PID != "302" {
+		t.Errorf("Children = %+v", app.Children)
+	}
+
+	worker, ok := app.ChildRoot(2)
+	if !ok || worker.PID != "302" || worker.Port != "8000" || worker.LogFile != "" || len(worker.Children) != 0 {
+		t.Errorf("ChildRoot(2) = %+v, %v", worker, ok)
+	}
+	if _, ok := app.ChildRoot(3); ok {
+		t.Error("ChildRoot(3) poza zakresem powinno zwrócić false")
+	}
+
+	if got[1].PID != "400" || len(got[1].Children) != 0 {
+		t.Errorf("osobne zadanie powłoki: %+v", got[1])
+	}
+	if got[2].PID != "501" || got[2].Command != "sh -c /opt/job.sh" {
+		t.Errorf("zadanie crona powinno mieć korzeń sh -c (PID 501): %+v", got[2])
+	}
+}
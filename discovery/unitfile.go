@@ -0,0 +1,265 @@
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"monitor_mutex/config"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Wpis jednostki systemd (klucze mogą się powtarzać, np. Environment=)
+type unitEntry struct {
+	section string
+	key     string
+	value   string
+}
+
+// Czyta plik jednostki systemd: komentarze # i ;, \ na końcu linii łączy ją z następną
+func parseUnitEntries(data []byte) ([]unitEntry, error) {
+	var entries []unitEntry
+	section := ""
+	pending := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if pending == "" && (line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";")) {
+			continue
+		}
+		if strings.HasSuffix(line, `\`) {
+			pending += strings.TrimSuffix(line, `\`) + " "
+			continue
+		}
+		line, pending = pending+line, ""
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line[1 : len(line)-1]
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section == "" {
+			return nil, fmt.Errorf("linia %d: oczekiwano Klucz=wartość w sekcji", n)
+		}
+		entries = append(entries, unitEntry{section: section, key: strings.TrimSpace(key), value: strings.TrimSpace(value)})
+	}
+	return entries, scanner.Err()
+}
+
+// Specyfikatory systemd w wartościach (%n, %i, %%...)
+var unitSpecifier = regexp.MustCompile(`%.`)
+
+// Przekłada usługę systemd na wpis konfiguracji
+func parseUnitFile(filename string, data []byte) ([]ImportedProcess, error) {
+	entries, err := parseUnitEntries(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	unitName := filepath.Base(filename)
+	prefix := strings.TrimSuffix(unitName, ".service")
+	instance := ""
+	if at := strings.Index(prefix, "@"); at >= 0 {
+		prefix, instance = prefix[:at], prefix[at+1:]
+	}
+
+	p := ImportedProcess{Source: filename, Config: config.ProcessConfig{Name: strings.TrimSuffix(unitName, ".service")}}
+	pc := &p.Config
+	note := func(format string, args ...interface{}) {
+		p.Unmapped = append(p.Unmapped, fmt.Sprintf(format, args...))
+	}
+
+	expand := func(e unitEntry) string {
+		return unitSpecifier.ReplaceAllStringFunc(e.value, func(s string) string {
+			switch s {
+			case "%%":
+				return "%"
+			case "%n":
+				return unitName
+			case "%N":
+				return strings.TrimSuffix(unitName, ".service")
+			case "%p":
+				return prefix
+			case "%i", "%I":
+				return instance
+			}
+			note("%s: specyfikator %s pozostawiony bez zmian", e.key, s)
+			return s
+		})
+	}
+
+	serviceType := "simple"
+	var watchdog config.WatchdogConfig
+	startTimeout := 0
+	stderrInherit := false
+	execStarts := 0
+
+	for _, e := range entries {
+		switch e.section {
+		case "Unit":
+			if e.key != "Description" && e.key != "Documentation" {
+				note("[Unit] %s=%s: monitor nie zna zależności między usługami", e.key, e.value)
+			}
+			continue
+		case "Install":
+			continue // Włączanie przy starcie systemu - monitor uruchamia wszystko od razu
+		case "Service":
+		default:
+			note("[%s]: sekcja pominięta", e.section)
+			continue
+		}
+
+		value := expand(e)
+		switch e.key {
+		case "Type":
+			serviceType = value
+			switch value {
+			case "simple", "exec":
+			case "notify", "notify-reload":
+				watchdog.Notify = true
+			default:
+				note("Type=%s: monitor oczekuje procesu działającego na pierwszym planie", value)
+			}
+		case "ExecStart":
+			execStarts++
+			if execStarts > 1 {
+				note("ExecStart=%s: monitor uruchamia jedną komendę", value)
+				continue
+			}
+			command := strings.TrimLeft(value, "-@:+!")
+			if strings.HasPrefix(value, "@") {
+				note("ExecStart=@...: pominięto podmianę argv[0]")
+				args := splitQuoted(command, unicode.IsSpace)
+				if len(args) > 1 {
+					args = append(args[:1], args[2:]...)
+				}
+				pc.Command = shellJoin(args)
+			} else {
+				pc.Command = shellJoin(splitQuoted(command, unicode.IsSpace))
+			}
+		case "WorkingDirectory":
+			dir := strings.TrimPrefix(value, "-")
+			if dir == "~" {
+				note("WorkingDirectory=~: katalog domowy użytkownika trzeba podać wprost")
+				continue
+			}
+			pc.WorkingDir = dir
+		case "User":
+			pc.User = value
+		case "Group":
+			pc.Group = value
+		case "Environment":
+			pc.Env = append(pc.Env, splitQuoted(value, unicode.IsSpace)...)
+		case "StandardOutput":
+			if file, ok := unitOutputFile(value); ok {
+				pc.Stdout = file
+			} else if value != "journal" && value != "inherit" {
+				note("StandardOutput=%s: wyjście trafi do pliku logów monitora", value)
+			}
+		case "StandardError":
+			if file, ok := unitOutputFile(value); ok {
+				pc.Stderr = file
+			} else if value == "inherit" {
+				stderrInherit = true
+			} else if value != "journal" {
+				note("StandardError=%s: wyjście trafi do pliku logów monitora", value)
+			}
+		case "Restart":
+			if value != "always" {
+				note("Restart=%s: monitor restartuje proces po każdym zakończeniu", value)
+			}
+		case "WatchdogSec":
+			seconds, ok := parseTimespan(value)
+			if !ok {
+				note("WatchdogSec=%s: nieobsługiwany zapis czasu", value)
+				continue
+			}
+			if seconds == 0 {
+				continue // Watchdog wyłączony
+			}
+			// Z WatchdogSec systemd przekazuje NOTIFY_SOCKET także usługom Type=simple
+			watchdog.Notify = true
+			watchdog.Timeout = seconds
+		case "TimeoutStartSec":
+			if seconds, ok := parseTimespan(value); ok {
+				startTimeout = seconds
+			} else {
+				note("TimeoutStartSec=%s: nieobsługiwany zapis czasu", value)
+			}
+		case "KillSignal":
+			if sig := strings.TrimPrefix(strings.ToUpper(value), "SIG"); sig != "TERM" {
+				note("KillSignal=%s: monitor zatrzymuje proces sygnałem SIGTERM", value)
+			}
+		case "TimeoutStopSec":
+			if seconds, ok := parseTimespan(value); !ok || seconds != 5 {
+				note("TimeoutStopSec=%s: monitor czeka 5 s przed SIGKILL", value)
+			}
+		case "NotifyAccess":
+			// Monitor przyjmuje komunikaty od każdego procesu
+		default:
+			note("%s=%s: brak odpowiednika", e.key, value)
+		}
+	}
+
+	if pc.Command == "" {
+		return nil, fmt.Errorf("%s: brak ExecStart w sekcji [Service]", filename)
+	}
+	if stderrInherit {
+		pc.Stderr = pc.Stdout
+	}
+	if watchdog.Notify {
+		if startTimeout > 0 && (serviceType == "notify" || serviceType == "notify-reload") {
+			watchdog.ReadyTimeout = startTimeout
+		}
+		pc.Watchdog = &watchdog
+	}
+	return []ImportedProcess{p}, nil
+}
+
+// Plik z StandardOutput=append:/ścieżka (także file: i truncate:)
+func unitOutputFile(value string) (string, bool) {
+	for _, prefix := range []string{"append:", "file:", "truncate:"} {
+		if file, ok := strings.CutPrefix(value, prefix); ok {
+			return file, true
+		}
+	}
+	return "", false
+}
+
+// Jednostki czasu systemd w sekundach
+var timespanUnits = map[string]float64{
+	"": 1, "s": 1, "sec": 1, "second": 1, "seconds": 1,
+	"ms": 0.001, "msec": 0.001,
+	"m": 60, "min": 60, "minute": 60, "minutes": 60,
+	"h": 3600, "hr": 3600, "hour": 3600, "hours": 3600,
+	"d": 86400, "day": 86400, "days": 86400,
+}
+
+var timespanPart = regexp.MustCompile(`^([0-9.]+)\s*([a-z]*)`)
+
+// Czas w zapisie systemd ("90", "1min 30s", "2h") w pełnych sekundach; infinity i 0 dają 0
+func parseTimespan(value string) (int, bool) {
+	value = strings.TrimSpace(value)
+	if value == "infinity" {
+		return 0, true
+	}
+	var total float64
+	for value != "" {
+		m := timespanPart.FindStringSubmatch(value)
+		if m == nil {
+			return 0, false
+		}
+		n, err := strconv.ParseFloat(m[1], 64)
+		unit, known := timespanUnits[m[2]]
+		if err != nil || !known {
+			return 0, false
+		}
+		total += n * unit
+		value = strings.TrimSpace(value[len(m[0]):])
+	}
+	return int(total + 0.5), true
+}
@@ -0,0 +1,135 @@
+package discovery
+
+import (
+	"encoding/binary"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// Stałe sock_diag z <linux/sock_diag.h> i <linux/unix_diag.h>
+const (
+	sockDiagByFamily = 20
+	unixDiagShowName = 0x01
+	unixDiagShowPeer = 0x04
+	unixDiagName     = 0
+	unixDiagPeer     = 2
+	unixDiagMsgLen   = 16 // family, type, state, pad, ino, cookie[2]
+)
+
+// Żądanie unix_diag_req
+type unixDiagReq struct {
+	Family   uint8
+	Protocol uint8
+	Pad      uint16
+	States   uint32
+	Ino      uint32
+	Show     uint32
+	Cookie   [2]uint32
+}
+
+// Zwraca dla połączonych gniazd unix: i-węzeł -> ścieżka gniazda po drugiej stronie
+//
+// Klient (np. stdout usługi podłączone do journald) ma gniazdo bez nazwy, więc
+// /proc/net/unix nie wystarcza - nazwę ma dopiero gniazdo serwera, znalezione przez
+// sock_diag (to samo źródło, z którego korzysta ss -x). Przy braku uprawnień lub
+// wsparcia jądra zwraca pustą mapę.
+func unixSocketPeers() map[uint64]string {
+	peers := make(map[uint64]string)
+
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, syscall.NETLINK_INET_DIAG) // = NETLINK_SOCK_DIAG
+	if err != nil {
+		return peers
+	}
+	defer syscall.Close(fd)
+
+	req := unixDiagReq{
+		Family: syscall.AF_UNIX,
+		States: ^uint32(0),
+		Show:   unixDiagShowName | unixDiagShowPeer,
+	}
+	reqBytes := (*[unsafe.Sizeof(req)]byte)(unsafe.Pointer(&req))[:]
+
+	hdr := syscall.NlMsghdr{
+		Len:   uint32(syscall.NLMSG_HDRLEN + len(reqBytes)),
+		Type:  sockDiagByFamily,
+		Flags: syscall.NLM_F_REQUEST | syscall.NLM_F_DUMP,
+		Seq:   1,
+	}
+	msg := make([]byte, 0, hdr.Len)
+	msg = append(msg, (*[syscall.NLMSG_HDRLEN]byte)(unsafe.Pointer(&hdr))[:]...)
+	msg = append(msg, reqBytes...)
+
+	if err := syscall.Sendto(fd, msg, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return peers
+	}
+
+	names := make(map[uint32]string)
+	links := make(map[uint32]uint32) // Gniazdo -> gniazdo po drugiej stronie
+	buf := make([]byte, 64*1024)
+
+recv:
+	for {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return peers
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return peers
+		}
+		for _, m := range msgs {
+			switch m.Header.Type {
+			case syscall.NLMSG_DONE:
+				break recv
+			case syscall.NLMSG_ERROR:
+				return peers
+			}
+			parseUnixDiagMsg(m.Data, names, links)
+		}
+	}
+
+	for ino, peer := range links {
+		if name := names[peer]; name != "" {
+			peers[uint64(ino)] = name
+		}
+	}
+	return peers
+}
+
+// Odczytuje i-węzeł, nazwę i rozmówcę z jednej odpowiedzi unix_diag_msg
+func parseUnixDiagMsg(data []byte, names map[uint32]string, links map[uint32]uint32) {
+	if len(data) < unixDiagMsgLen {
+		return
+	}
+	ino := binary.NativeEndian.Uint32(data[4:8])
+
+	// Atrybuty rtattr: długość (2 bajty), typ (2 bajty), dane wyrównane do 4 bajtów
+	for attrs := data[unixDiagMsgLen:]; len(attrs) >= 4; {
+		attrLen := int(binary.NativeEndian.Uint16(attrs[0:2]))
+		attrType := binary.NativeEndian.Uint16(attrs[2:4])
+		if attrLen < 4 || attrLen > len(attrs) {
+			return
+		}
+		value := attrs[4:attrLen]
+
+		switch attrType {
+		case unixDiagName:
+			name := strings.TrimRight(string(value), "\x00")
+			if len(value) > 0 && value[0] == 0 {
+				name = "@" + strings.TrimRight(string(value[1:]), "\x00") // Gniazdo abstrakcyjne
+			}
+			names[ino] = name
+		case unixDiagPeer:
+			if len(value) >= 4 {
+				links[ino] = binary.NativeEndian.Uint32(value)
+			}
+		}
+
+		aligned := (attrLen + 3) &^ 3
+		if aligned >= len(attrs) {
+			return
+		}
+		attrs = attrs[aligned:]
+	}
+}
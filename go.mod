@@ -0,0 +1,5 @@
+module monitor_mutex
+
+go 1.21
+
+require gopkg.in/yaml.v2 v2.4.0
@@ -0,0 +1,90 @@
+// Pakiet procfs czyta /proc/<pid>/stat - wspólny parser dla monitora i discovery,
+// żeby nazwa procesu ze spacjami i nawiasami była obsługiwana w jednym miejscu.
+package procfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Pola /proc/<pid>/stat używane przez monitor i discovery
+type Stat struct {
+	PID       int
+	Comm      string // Nazwa procesu (może zawierać spacje i nawiasy)
+	State     string // R, S, D, Z...
+	PPID      int
+	PGRP      int    // Grupa procesów
+	Session   int    // Sesja (setsid)
+	TTY       int    // Terminal sterujący (0 = brak)
+	UTime     uint64 // Czas CPU w trybie użytkownika (takty zegara)
+	STime     uint64 // Czas CPU w trybie jądra (takty zegara)
+	StartTime uint64 // Czas startu w taktach od uruchomienia systemu
+	RSSPages  int64  // Pamięć rezydentna w stronach
+}
+
+// Odczytuje /proc/<pid>/stat
+func ReadStat(pid int) (Stat, error) {
+	path := filepath.Join("/proc", strconv.Itoa(pid), "stat")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Stat{}, err
+	}
+	st, err := ParseStat(data)
+	if err != nil {
+		return Stat{}, fmt.Errorf("%s: %v", path, err)
+	}
+	return st, nil
+}
+
+// Parsuje zawartość /proc/<pid>/stat
+//
+// Nazwa w nawiasach może zawierać spacje i nawiasy - pola są liczone od ostatniego ')'.
+func ParseStat(data []byte) (Stat, error) {
+	stat := strings.TrimSpace(string(data))
+	open := strings.Index(stat, "(")
+	end := strings.LastIndex(stat, ")")
+	if open < 0 || end < open {
+		return Stat{}, fmt.Errorf("nieprawidłowy format: brak nazwy w nawiasach")
+	}
+
+	var st Stat
+	var err error
+	if st.PID, err = strconv.Atoi(strings.TrimSpace(stat[:open])); err != nil {
+		return Stat{}, fmt.Errorf("nieprawidłowy PID: %v", err)
+	}
+	st.Comm = stat[open+1 : end]
+
+	// fields[0] to pole 3 (stan): ppid = 4, pgrp = 5, session = 6, tty_nr = 7,
+	// utime = 14, stime = 15, starttime = 22, rss = 24
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 22 {
+		return Stat{}, fmt.Errorf("nieprawidłowy format: %d pól po nazwie, oczekiwano co najmniej 22", len(fields))
+	}
+
+	st.State = fields[0]
+	ints := []struct {
+		field int
+		dst   *int
+	}{{1, &st.PPID}, {2, &st.PGRP}, {3, &st.Session}, {4, &st.TTY}}
+	for _, f := range ints {
+		if *f.dst, err = strconv.Atoi(fields[f.field]); err != nil {
+			return Stat{}, fmt.Errorf("pole %d: %v", f.field+3, err)
+		}
+	}
+	uints := []struct {
+		field int
+		dst   *uint64
+	}{{11, &st.UTime}, {12, &st.STime}, {19, &st.StartTime}}
+	for _, f := range uints {
+		if *f.dst, err = strconv.ParseUint(fields[f.field], 10, 64); err != nil {
+			return Stat{}, fmt.Errorf("pole %d: %v", f.field+3, err)
+		}
+	}
+	if st.RSSPages, err = strconv.ParseInt(fields[21], 10, 64); err != nil {
+		return Stat{}, fmt.Errorf("pole 24: %v", err)
+	}
+	return st, nil
+}
@@ -0,0 +1,50 @@
+package procfs
+
+import (
+	"os"
+	"testing"
+)
+
+// Pola za nazwą od stanu (pole 3) do rss (pole 24) i dalej
+const statTail = "S 100 200 300 34816 -1 4194560 1000 0 0 0 15 7 0 0 20 0 1 0 123456 10000000 250 18446744073709551615"
+
+// Nazwa ze spacjami i nawiasami nie przesuwa pól
+func TestParseStat(t *testing.T) {
+	for _, comm := range []string{"sleep", "my app", "a) b (c", "))", "x) S 1 2 3"} {
+		st, err := ParseStat([]byte("4242 (" + comm + ") " + statTail + "\n"))
+		if err != nil {
+			t.Fatalf("%q: %v", comm, err)
+		}
+		want := Stat{PID: 4242, Comm: comm, State: "S", PPID: 100, PGRP: 200, Session: 300, TTY: 34816,
+			UTime: 15, STime: 7, StartTime: 123456, RSSPages: 250}
+		if st != want {
+			t.Errorf("%q:\n%+v\noczekiwano\n%+v", comm, st, want)
+		}
+	}
+
+	for name, data := range map[string]string{
+		"brak nawiasów":   "4242 sleep " + statTail,
+		"za mało pól":     "4242 (sleep) S 100 200",
+		"zły PID":         "abc (sleep) " + statTail,
+		"liczba w polu":   "4242 (sleep) S x 200 300 34816 -1 4194560 1000 0 0 0 15 7 0 0 20 0 1 0 123456 10000000 250",
+		"pusta zawartość": "",
+	} {
+		if _, err := ParseStat([]byte(data)); err == nil {
+			t.Errorf("%s: oczekiwano błędu", name)
+		}
+	}
+}
+
+// Odczyt własnego procesu
+func TestReadStat(t *testing.T) {
+	st, err := ReadStat(os.Getpid())
+	if err != nil {
+		t.Fatalf("ReadStat: %v", err)
+	}
+	if st.PID != os.Getpid() || st.PPID != os.Getppid() || st.StartTime == 0 || st.RSSPages == 0 {
+		t.Errorf("własny proces: %+v", st)
+	}
+	if _, err := ReadStat(-1); err == nil {
+		t.Error("oczekiwano błędu dla nieistniejącego procesu")
+	}
+}
@@ -0,0 +1,193 @@
+package supervisor
+
+import (
+	"fmt"
+	"math"
+	"monitor_mutex/config"
+	"sort"
+	"time"
+)
+
+// Domyślne parametry adaptive_timeout
+const (
+	defaultAdaptivePercentile = 99
+	defaultAdaptiveMargin     = 2.0
+	defaultAdaptiveHistory    = 500
+	defaultAdaptiveMinSamples = 20
+	adaptiveSaveEvery         = 10 // Zapisuj historię w pliku stanu co tyle nowych przerw
+)
+
+// Adaptacyjny timeout: rozkład ostatnich przerw między zapisami do logu
+//
+// Cisza dłuższa niż margin × percentyl historii (w granicach min/max) jest anomalią.
+// Do zebrania min_samples przerw obowiązuje stały timeout z konfiguracji.
+type adaptiveTimeout struct {
+	min        time.Duration
+	max        time.Duration
+	percentile int
+	margin     float64
+	minSamples int
+
+	gaps      []time.Duration // Bufor cykliczny przerw
+	next      int             // Indeks następnego zapisu
+	full      bool            // Bufor został zapełniony co najmniej raz
+	lastWrite time.Time       // Ostatni zapis do logu (zero = nie licz następnej przerwy)
+	unsaved   int             // Przerwy dodane od ostatniego zapisu stanu
+}
+
+// Sprawdza konfigurację i uzupełnia wartości domyślne
+func newAdaptiveTimeout(cfg config.AdaptiveTimeoutConfig, timeout, interval time.Duration) (*adaptiveTimeout, error) {
+	a := &adaptiveTimeout{
+		min:        time.Duration(cfg.Min) * time.Second,
+		max:        time.Duration(cfg.Max) * time.Second,
+		percentile: cfg.Percentile,
+		margin:     cfg.Margin,
+		minSamples: cfg.MinSamples,
+	}
+	history := cfg.History
+
+	if a.min == 0 {
+		a.min = 3 * interval
+	}
+	if a.max == 0 {
+		a.max = 10 * timeout
+	}
+	if a.percentile == 0 {
+		a.percentile = defaultAdaptivePercentile
+	}
+	if a.margin == 0 {
+		a.margin = defaultAdaptiveMargin
+	}
+	if a.minSamples == 0 {
+		a.minSamples = defaultAdaptiveMinSamples
+	}
+	if history == 0 {
+		history = defaultAdaptiveHistory
+	}
+
+	switch {
+	case a.min < interval:
+		return nil, fmt.Errorf("min (%v) nie może być mniejsze niż interval (%v)", a.min, interval)
+	case a.max < a.min:
+		return nil, fmt.Errorf("max (%v) nie może być mniejsze niż min (%v)", a.max, a.min)
+	case a.percentile < 1 || a.percentile > 100:
+		return nil, fmt.Errorf("percentile musi być z zakresu 1-100, jest %d", a.percentile)
+	case a.margin < 1:
+		return nil, fmt.Errorf("margin musi być co najmniej 1, jest %g", a.margin)
+	case a.minSamples < 1 || history < a.minSamples:
+		return nil, fmt.Errorf("history (%d) musi być co najmniej min_samples (%d)", history, a.minSamples)
+	}
+
+	a.gaps = make([]time.Duration, history)
+	return a, nil
+}
+
+// Dodaje przerwę do bufora, nadpisując najstarszą po zapełnieniu
+func (a *adaptiveTimeout) add(gap time.Duration) {
+	a.gaps[a.next] = gap
+	a.next = (a.next + 1) % len(a.gaps)
+	if a.next == 0 {
+		a.full = true
+	}
+}
+
+// Zapamiętuje zapis do logu; zwraca true, gdy historię warto zapisać w pliku stanu
+func (a *adaptiveTimeout) observe(now time.Time) bool {
+	defer func() { a.lastWrite = now }()
+	if a.lastWrite.IsZero() {
+		return false
+	}
+	a.add(now.Sub(a.lastWrite))
+	a.unsaved++
+	if a.unsaved >= adaptiveSaveEvery {
+		a.unsaved = 0
+		return true
+	}
+	return false
+}
+
+// Przerwa po starcie procesu lub oknie serwisowym nie jest zwykłą ciszą - nie licz jej
+func (a *adaptiveTimeout) reset() {
+	a.lastWrite = time.Time{}
+}
+
+// Zebrane przerwy od najstarszej do najnowszej
+func (a *adaptiveTimeout) samples() []time.Duration {
+	if !a.full {
+		return append([]time.Duration(nil), a.gaps[:a.next]...)
+	}
+	return append(append([]time.Duration(nil), a.gaps[a.next:]...), a.gaps[:a.next]...)
+}
+
+// Percentyl przerw metodą najbliższej rangi (0 bez próbek)
+func (a *adaptiveTimeout) gapPercentile(p int) time.Duration {
+	sorted := a.samples()
+	if len(sorted) == 0 {
+		return 0
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Wyuczony timeout; false, dopóki historia jest za krótka
+func (a *adaptiveTimeout) learned() (time.Duration, bool) {
+	if len(a.samples()) < a.minSamples {
+		return 0, false
+	}
+	timeout := time.Duration(a.margin * float64(a.gapPercentile(a.percentile)))
+	if timeout < a.min {
+		timeout = a.min
+	}
+	if timeout > a.max {
+		timeout = a.max
+	}
+	return timeout.Round(time.Second), true
+}
+
+// Historia przerw w sekundach do pliku stanu
+func (a *adaptiveTimeout) export() []float64 {
+	samples := a.samples()
+	seconds := make([]float64, len(samples))
+	for i, gap := range samples {
+		seconds[i] = math.Round(gap.Seconds()*1000) / 1000
+	}
+	return seconds
+}
+
+// Wczytuje historię z pliku stanu (najnowsze przerwy, jeśli jest ich więcej niż miejsca)
+func (a *adaptiveTimeout) load(seconds []float64) {
+	if len(seconds) > len(a.gaps) {
+		seconds = seconds[len(seconds)-len(a.gaps):]
+	}
+	for _, s := range seconds {
+		a.add(time.Duration(s * float64(time.Second)))
+	}
+}
+
+// Timeout obowiązujący w tej chwili: wyuczony albo stały z konfiguracji
+func (m *Monitor) effectiveTimeout() time.Duration {
+	if m.adaptive != nil {
+		if timeout, ok := m.adaptive.learned(); ok {
+			return timeout
+		}
+	}
+	return m.timeout
+}
+
+// Zapamiętuje zapis do logu w historii adaptive_timeout
+func (m *Monitor) observeLogWrite(now time.Time) {
+	if m.adaptive != nil && m.adaptive.observe(now) {
+		m.saveState()
+	}
+}
+
+// Zapomina moment ostatniego zapisu - następna przerwa nie trafi do historii
+func (m *Monitor) resetLogGap() {
+	if m.adaptive != nil {
+		m.adaptive.reset()
+	}
+}
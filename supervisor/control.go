@@ -0,0 +1,233 @@
+package supervisor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"monitor_mutex/config"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Domyślna nazwa gniazda sterującego (obok pliku konfiguracyjnego)
+const defaultControlSocketName = "monitor_mutex.sock"
+
+// Limit czasu pojedynczej rozmowy przez gniazdo sterujące
+const controlTimeout = 10 * time.Second
+
+// Żądanie wysyłane do działającego monitora (jedna linia JSON)
+type controlRequest struct {
+	Command string `json:"command"`
+	Process string `json:"process,omitempty"` // Nazwa procesu dla restart/stop/start/scale
+	Count   int    `json:"count,omitempty"`   // Liczba kopii dla scale
+}
+
+// Odpowiedź działającego monitora
+type controlResponse struct {
+	OK        bool            `json:"ok"`
+	Error     string          `json:"error,omitempty"`
+	Processes []ProcessStatus `json:"processes,omitempty"`
+}
+
+// Serwer gniazda sterującego - udostępnia stan monitorów innym poleceniom
+type controlServer struct {
+	path     string
+	listener net.Listener
+	sup      *Supervisor
+}
+
+// Ustala ścieżkę gniazda: z konfiguracji albo obok pliku konfiguracyjnego
+func ControlSocketPath(configFile string, cfg *config.Config) string {
+	if cfg.ControlSocket != "" {
+		return cfg.ControlSocket
+	}
+	return filepath.Join(filepath.Dir(configFile), defaultControlSocketName)
+}
+
+// Uruchamia serwer gniazda sterującego
+func startControlServer(path string, sup *Supervisor) (*controlServer, error) {
+	// Pozostałość po poprzedniej instancji - usuń tylko, jeśli nikt nie nasłuchuje
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("gniazdo %s jest używane przez inny monitor", path)
+		}
+		os.Remove(path)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("nie można utworzyć gniazda sterującego %s: %v", path, err)
+	}
+
+	s := &controlServer{path: path, listener: listener, sup: sup}
+	go s.serve()
+	return s, nil
+}
+
+// Obsługuje połączenia aż do zamknięcia gniazda
+func (s *controlServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// Obsługuje pojedyncze połączenie: jedno żądanie, jedna odpowiedź
+func (s *controlServer) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(controlTimeout))
+
+	var req controlRequest
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		json.NewEncoder(conn).Encode(controlResponse{Error: fmt.Sprintf("nieprawidłowe żądanie: %v", err)})
+		return
+	}
+
+	json.NewEncoder(conn).Encode(s.dispatch(req))
+}
+
+// Wykonuje polecenie
+func (s *controlServer) dispatch(req controlRequest) controlResponse {
+	switch req.Command {
+	case "status":
+		return controlResponse{OK: true, Processes: collectStatuses(s.sup.Monitors())}
+	case ActionRestart, ActionStop, ActionStart:
+		m := s.find(req.Process)
+		if m == nil {
+			return controlResponse{Error: fmt.Sprintf("nieznany proces %q", req.Process)}
+		}
+		if err := m.Control(req.Command); err != nil {
+			return controlResponse{Error: err.Error()}
+		}
+		return controlResponse{OK: true, Processes: []ProcessStatus{m.Status()}}
+	case ActionScale:
+		monitors, err := s.sup.Scale(req.Process, req.Count)
+		if err != nil {
+			return controlResponse{Error: err.Error()}
+		}
+		return controlResponse{OK: true, Processes: collectStatuses(monitors)}
+	default:
+		return controlResponse{Error: fmt.Sprintf("nieznane polecenie %q", req.Command)}
+	}
+}
+
+// Szuka monitora po nazwie procesu
+func (s *controlServer) find(name string) *Monitor {
+	for _, m := range s.sup.Monitors() {
+		if m.displayName() == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// Zamyka gniazdo i usuwa plik
+func (s *controlServer) Close() {
+	s.listener.Close()
+	os.Remove(s.path)
+}
+
+// Wysyła żądanie do działającego monitora
+func sendControlRequest(path string, req controlRequest) (*controlResponse, error) {
+	conn, err := net.DialTimeout("unix", path, controlTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("nie można połączyć się z monitorem (%s): %v", path, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(controlTimeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+
+	var resp controlResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("nieprawidłowa odpowiedź monitora: %v", err)
+	}
+	if !resp.OK {
+		return nil, fmt.Errorf("monitor zwrócił błąd: %s", resp.Error)
+	}
+	return &resp, nil
+}
+
+// Wysyła polecenie (status, restart, stop, start) do działającego monitora i zwraca statusy procesów
+func SendCommand(socketPath, command, process string) ([]ProcessStatus, error) {
+	resp, err := sendControlRequest(socketPath, controlRequest{Command: command, Process: process})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Processes, nil
+}
+
+// Zmienia liczbę kopii wpisu z instances w działającym monitorze i zwraca statusy jego kopii
+func SendScale(socketPath, process string, count int) ([]ProcessStatus, error) {
+	resp, err := sendControlRequest(socketPath, controlRequest{Command: ActionScale, Process: process, Count: count})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Processes, nil
+}
+
+// Ręczne polecenia wykonywane przez pętlę Run monitora
+const (
+	ActionRestart = "restart"
+	ActionStop    = "stop"
+	ActionStart   = "start"
+	ActionScale   = "scale" // Obsługiwane przez Supervisor, nie przez pętlę monitora
+)
+
+// Polecenie przekazywane do pętli Run
+type monitorCommand struct {
+	action string
+	reply  chan error
+}
+
+// Wykonuje polecenie - wywoływane wyłącznie z pętli Run
+func (m *Monitor) handleCommand(action string) error {
+	switch action {
+	case ActionStop:
+		m.held = true
+		m.killProcess()
+		fmt.Printf("Proces %s zatrzymany ręcznie\n", m.displayName())
+		return nil
+
+	case ActionStart:
+		if m.isProcessRunning() {
+			return fmt.Errorf("proces %s już działa", m.displayName())
+		}
+		m.held = false
+		return m.startProcess()
+
+	case ActionRestart:
+		m.held = false
+		if err := m.startProcess(); err != nil {
+			return err
+		}
+		m.recordRestart("ręczny restart")
+		m.notify(eventRestart, "ręczny restart", 1)
+		return nil
+
+	default:
+		return fmt.Errorf("nieznane polecenie %q", action)
+	}
+}
+
+// Zleca polecenie pętli Run i czeka na wynik (bezpieczne z dowolnej goroutine)
+func (m *Monitor) Control(action string) error {
+	reply := make(chan error, 1)
+
+	select {
+	case m.commands <- monitorCommand{action: action, reply: reply}:
+		return <-reply
+	case <-m.finished:
+		return fmt.Errorf("monitor %s zakończył działanie", m.displayName())
+	case <-time.After(statusReplyTimeout):
+		return fmt.Errorf("monitor %s nie odpowiada", m.displayName())
+	}
+}
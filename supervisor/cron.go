@@ -0,0 +1,190 @@
+package supervisor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Harmonogram w formacie cron (minuta godzina dzień miesiąc dzień_tygodnia)
+type cronSchedule struct {
+	minute uint64 // Bity 0-59
+	hour   uint64 // Bity 0-23
+	dom    uint64 // Bity 1-31
+	month  uint64 // Bity 1-12
+	dow    uint64 // Bity 0-6 (0 = niedziela)
+	domAny bool   // Pole dnia miesiąca zaczyna się od "*"
+	dowAny bool   // Pole dnia tygodnia zaczyna się od "*"
+}
+
+// Skróty obsługiwane zamiast pięciu pól
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Jak daleko w przyszłość szukać pasującego terminu
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// Parsuje wyrażenie cron, np. "30 3 * * 1-5" albo "@daily"
+func parseCron(expr string) (*cronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[expr]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("wyrażenie cron %q musi mieć 5 pól, ma %d", expr, len(fields))
+	}
+
+	var s cronSchedule
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("pole minut: %v", err)
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("pole godzin: %v", err)
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("pole dnia miesiąca: %v", err)
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("pole miesiąca: %v", err)
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("pole dnia tygodnia: %v", err)
+	}
+
+	// 7 to również niedziela
+	if s.dow&(1<<7) != 0 {
+		s.dow = (s.dow | 1) &^ (1 << 7)
+	}
+	// Jak w vixie cron: pole zaczynające się od "*" (także "*/2") nie zawęża dni
+	s.domAny = strings.HasPrefix(fields[2], "*")
+	s.dowAny = strings.HasPrefix(fields[4], "*")
+
+	// Wyrażenia typu "0 0 31 2 *" nigdy się nie wykonają
+	if s.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("wyrażenie cron %q nigdy nie pasuje do żadnego terminu", expr)
+	}
+
+	return &s, nil
+}
+
+// Parsuje pojedyncze pole: "*", "5", "1-5", "*/15", "0-30/10", "1,15,30"
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("nieprawidłowy krok w %q", part)
+			}
+			rangePart, step = part[:i], n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(bounds[0])
+			hi, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("nieprawidłowy zakres %q", rangePart)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("nieprawidłowa wartość %q", rangePart)
+			}
+			lo, hi = n, n
+			// "5/10" oznacza od 5 do końca zakresu co 10
+			if step > 1 {
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("wartość %q poza zakresem %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// Sprawdza czy dzień pasuje (reguła cron: jeśli oba pola są zawężone, wystarczy jedno)
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domOk := s.dom&(1<<uint(t.Day())) != 0
+	dowOk := s.dow&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dowOk
+	case s.dowAny:
+		return domOk
+	default:
+		return domOk || dowOk
+	}
+}
+
+// Zwraca pierwszy pasujący termin ściśle po podanym czasie (zero, jeśli brak)
+func (s *cronSchedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute(), 0, 0, loc).Add(time.Minute)
+	limit := after.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// Okno serwisowe - czas, w którym cisza w logach nie powoduje restartu
+type maintenanceWindow struct {
+	schedule *cronSchedule // Początek okna
+	duration time.Duration // Długość okna
+}
+
+// Sprawdza czy okno trwa w danej chwili; zwraca też czas jego zakończenia
+func (w maintenanceWindow) activeAt(now time.Time) (time.Time, bool) {
+	start := w.schedule.Next(now.Add(-w.duration))
+	if start.IsZero() || start.After(now) {
+		return time.Time{}, false
+	}
+	return start.Add(w.duration), true
+}
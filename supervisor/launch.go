@@ -0,0 +1,171 @@
+package supervisor
+
+import (
+	"fmt"
+	"monitor_mutex/config"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Sposób uruchomienia procesu: katalog roboczy, środowisko, użytkownik i przekierowania
+type launchSpec struct {
+	dir        string              // Katalog roboczy (pusty = katalog monitora)
+	env        []string            // Pełne środowisko (nil = dziedziczone z monitora)
+	credential *syscall.Credential // Użytkownik i grupy (nil = jak monitor)
+	stdout     string              // Plik dopisywania stdout (pusty = /dev/null)
+	stderr     string              // Plik dopisywania stderr
+}
+
+// Sprawdza i przygotowuje ustawienia uruchomienia z konfiguracji procesu
+func newLaunchSpec(pc config.ProcessConfig) (launchSpec, error) {
+	spec := launchSpec{
+		dir:    pc.WorkingDir,
+		stdout: pc.Stdout,
+		stderr: pc.Stderr,
+	}
+
+	if pc.WorkingDir != "" {
+		if info, err := os.Stat(pc.WorkingDir); err != nil || !info.IsDir() {
+			return spec, fmt.Errorf("working_dir %s nie jest katalogiem", pc.WorkingDir)
+		}
+	}
+
+	var account *user.User
+	if pc.User != "" || pc.Group != "" {
+		cred, u, err := lookupCredential(pc.User, pc.Group)
+		if err != nil {
+			return spec, err
+		}
+		spec.credential = cred
+		account = u
+	}
+
+	if len(pc.Env) > 0 || account != nil {
+		env := os.Environ()
+		if account != nil {
+			// Jak login: środowisko użytkownika, nie monitora
+			env = setEnv(env, "HOME="+account.HomeDir)
+			env = setEnv(env, "USER="+account.Username)
+			env = setEnv(env, "LOGNAME="+account.Username)
+		}
+		for _, entry := range pc.Env {
+			if key, _, ok := strings.Cut(entry, "="); !ok || key == "" {
+				return spec, fmt.Errorf("env: wpis %q nie ma postaci KLUCZ=wartość", entry)
+			}
+			env = setEnv(env, entry)
+		}
+		spec.env = env
+	}
+
+	return spec, nil
+}
+
+// Ustala UID/GID dla user i group (nazwy albo numery)
+func lookupCredential(userName, groupName string) (*syscall.Credential, *user.User, error) {
+	cred := &syscall.Credential{
+		Uid: uint32(os.Getuid()),
+		Gid: uint32(os.Getgid()),
+	}
+	var account *user.User
+
+	if userName != "" {
+		u, err := user.Lookup(userName)
+		if err != nil {
+			if u, err = user.LookupId(userName); err != nil {
+				return nil, nil, fmt.Errorf("user: nieznany użytkownik %s", userName)
+			}
+		}
+		uid, _ := strconv.ParseUint(u.Uid, 10, 32)
+		gid, _ := strconv.ParseUint(u.Gid, 10, 32)
+		cred.Uid, cred.Gid = uint32(uid), uint32(gid)
+
+		if ids, err := u.GroupIds(); err == nil {
+			for _, id := range ids {
+				if g, err := strconv.ParseUint(id, 10, 32); err == nil {
+					cred.Groups = append(cred.Groups, uint32(g))
+				}
+			}
+		}
+		account = u
+	}
+
+	if groupName != "" {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			if g, err = user.LookupGroupId(groupName); err != nil {
+				return nil, nil, fmt.Errorf("group: nieznana grupa %s", groupName)
+			}
+		}
+		gid, _ := strconv.ParseUint(g.Gid, 10, 32)
+		cred.Gid = uint32(gid)
+	}
+
+	// Bez roota setuid/setgid się nie uda - lepiej powiedzieć to przy starcie niż przy każdym restarcie
+	if os.Geteuid() != 0 && (cred.Uid != uint32(os.Geteuid()) || cred.Gid != uint32(os.Getegid())) {
+		return nil, nil, fmt.Errorf("user/group: zmiana użytkownika lub grupy wymaga uruchomienia monitora jako root")
+	}
+	if cred.Uid == uint32(os.Getuid()) && len(cred.Groups) == 0 {
+		cred.NoSetGroups = true // Ten sam użytkownik - zostaw grupy dodatkowe monitora
+	}
+
+	return cred, account, nil
+}
+
+// Ustawia zmienną w środowisku, zastępując poprzednią wartość
+func setEnv(env []string, entry string) []string {
+	key, _, _ := strings.Cut(entry, "=")
+	for i, existing := range env {
+		if k, _, _ := strings.Cut(existing, "="); k == key {
+			env[i] = entry
+			return env
+		}
+	}
+	return append(env, entry)
+}
+
+// Konfiguruje komendę; zwraca otwarte pliki przekierowań do zamknięcia po Start
+func (l launchSpec) apply(cmd *exec.Cmd) ([]*os.File, error) {
+	cmd.Dir = l.dir
+	cmd.Env = l.env
+	if l.credential != nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Credential: l.credential}
+	}
+
+	var files []*os.File
+	open := func(path string) (*os.File, error) {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			for _, opened := range files {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("nie można otworzyć %s: %v", path, err)
+		}
+		files = append(files, f)
+		return f, nil
+	}
+
+	if l.stdout != "" {
+		f, err := open(l.stdout)
+		if err != nil {
+			return nil, err
+		}
+		cmd.Stdout = f
+	}
+	if l.stderr != "" {
+		if l.stderr == l.stdout {
+			cmd.Stderr = cmd.Stdout // 2>&1 - jeden deskryptor, kolejność wpisów zachowana
+		} else {
+			f, err := open(l.stderr)
+			if err != nil {
+				return nil, err
+			}
+			cmd.Stderr = f
+		}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,760 @@
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"monitor_mutex/config"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Struktura przechowująca konfigurację monitora
+type Monitor struct {
+	name        string        // Nazwa procesu z konfiguracji
+	command     string        // Komenda do uruchomienia
+	logFile     string        // Ścieżka do pliku logów
+	timeout     time.Duration // Jak długo czekać bez zmian w logach
+	interval    time.Duration // Jak często sprawdzać
+	process     *exec.Cmd     // Wskaźnik do uruchomionego procesu
+	lastModTime time.Time     // Kiedy ostatnio zmieniły się logi
+	lastLogSize int64         // Ostatni rozmiar pliku logów
+	mutex       sync.RWMutex  // Mutex do synchronizacji dostępu do procesu
+	ctx         context.Context
+	cancel      context.CancelFunc
+	retryCount  int           // Licznik nieudanych prób
+	maxRetries  int           // Maksymalna liczba prób (3)
+	lastFailure time.Time     // Czas ostatniej nieudanej próby
+
+	restartSchedule    *cronSchedule       // Zaplanowane restarty (nil = brak)
+	maintenanceWindows []maintenanceWindow // Okna serwisowe
+	notifier           *notifier           // Powiadomienia (nil = wyłączone)
+	launch             launchSpec          // Katalog, środowisko, użytkownik i przekierowania
+	adaptive           *adaptiveTimeout    // Timeout wyuczony z historii przerw (nil = stały timeout)
+	watchdog           *watchdog           // Gotowość i sygnały życia od aplikacji (nil = tylko logi)
+
+	state    *stateStore  // Trwały stan (nil = tryb bez pliku stanu)
+	restarts *restartRing // Historia ostatnich restartów
+	adopted  bool         // Proces przejęty po restarcie monitora (nie jest naszym dzieckiem)
+
+	startedAt    time.Time               // Kiedy uruchomiono bieżący proces
+	exited       chan struct{}           // Zamykany, gdy nasz proces potomny się zakończy
+	exitMu       sync.Mutex              // Chroni lastExitCode (zapisywany przez goroutine reap)
+	lastExitCode *int                    // Kod wyjścia ostatniego zakończonego procesu
+	statusReq    chan chan ProcessStatus // Żądania statusu obsługiwane w pętli Run
+	finished     chan struct{}           // Zamykany po zakończeniu Run
+	finalStatus  ProcessStatus           // Status w chwili zakończenia Run
+
+	commands chan monitorCommand // Ręczne polecenia (restart/stop/start) obsługiwane w pętli Run
+	held     bool                // Proces zatrzymany ręcznie - nie restartuj automatycznie
+}
+
+// Stałe zachowania restartu (odtwarzane też przez export systemd)
+const (
+	defaultMaxRetries     = 3               // Próby restartu, zanim monitor się podda
+	stableIterationsReset = 10              // Po tylu spokojnych sprawdzeniach licznik prób wraca do zera
+	stopTimeout           = 5 * time.Second // Czas na zakończenie po SIGTERM, potem SIGKILL
+)
+
+// Konstruktor - tworzy nową instancję monitora
+func NewMonitor(command, logFile string, timeout, interval int) *Monitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Monitor{
+		command:    command,
+		logFile:    logFile,
+		timeout:    time.Duration(timeout) * time.Second,
+		interval:   time.Duration(interval) * time.Second,
+		ctx:        ctx,
+		cancel:     cancel,
+		maxRetries: defaultMaxRetries,
+		retryCount: 0,
+		restarts:   newRestartRing(maxRestartHistory),
+		statusReq:  make(chan chan ProcessStatus),
+		finished:   make(chan struct{}),
+		commands:   make(chan monitorCommand),
+	}
+}
+
+// Pobiera informacje o pliku logów (czas modyfikacji i rozmiar)
+func (m *Monitor) getLogInfo() (time.Time, int64, error) {
+	info, err := os.Stat(m.logFile)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return info.ModTime(), info.Size(), nil
+}
+
+// Sprawdza czy w logach pojawiły się nowe wpisy
+func (m *Monitor) checkLogs() (bool, error) {
+	modTime, size, err := m.getLogInfo()
+	if err != nil {
+		return false, fmt.Errorf("nie można odczytać pliku logów: %v", err)
+	}
+
+	// Pierwsza iteracja - zapisz początkowy stan
+	if m.lastModTime.IsZero() {
+		m.lastModTime = modTime
+		m.lastLogSize = size
+		fmt.Printf("Początkowy stan logów: rozmiar %d bajtów\n", size)
+		return true, nil
+	}
+
+	// Sprawdź czy plik urósł (nowe logi)
+	if size > m.lastLogSize {
+		fmt.Printf("Nowe logi: rozmiar %d -> %d bajtów (+%d)\n",
+			m.lastLogSize, size, size-m.lastLogSize)
+		m.lastModTime = time.Now()
+		m.lastLogSize = size
+		m.observeLogWrite(m.lastModTime)
+		// Reset retry counter na sukces
+		if m.retryCount > 0 {
+			m.retryCount = 0
+			m.saveState()
+		}
+		return true, nil
+	}
+
+	// Sprawdź czy plik się zmienił (może został przepisany)
+	if modTime.After(m.lastModTime) {
+		fmt.Printf("Plik logów zaktualizowany: %s\n", modTime.Format("15:04:05"))
+		m.lastModTime = modTime
+		m.lastLogSize = size
+		m.observeLogWrite(time.Now())
+		// Reset retry counter na sukces
+		if m.retryCount > 0 {
+			m.retryCount = 0
+			m.saveState()
+		}
+		return true, nil
+	}
+
+	// Sprawdź czy minął timeout bez zmian
+	timeout := m.effectiveTimeout()
+	timeSinceLastChange := time.Since(m.lastModTime)
+	if timeSinceLastChange > timeout {
+		fmt.Printf("TIMEOUT! Brak zmian w logach przez %v (limit: %v)\n",
+			timeSinceLastChange.Round(time.Second), timeout)
+		if timeout != m.timeout {
+			fmt.Printf("Limit wyuczony z %d przerw, %d. percentyl: %v\n", len(m.adaptive.samples()),
+				m.adaptive.percentile, m.adaptive.gapPercentile(m.adaptive.percentile).Round(time.Second))
+		}
+		return false, nil
+	}
+
+	// Pokazuj co jakiś czas status oczekiwania
+	if int(timeSinceLastChange.Seconds())%30 == 0 && timeSinceLastChange > 30*time.Second {
+		fmt.Printf("Oczekiwanie na zmiany w logach... (%v/%v)\n",
+			timeSinceLastChange.Round(time.Second), timeout)
+	}
+
+	return true, nil
+}
+
+// Uruchamia nowy proces z obsługą retry
+func (m *Monitor) startProcess() error {
+	// Zapis stanu po zwolnieniu locka (defer wykonuje się w odwrotnej kolejności)
+	defer m.saveState()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Jeśli jakiś proces już działa, zabij go
+	if m.process != nil {
+		m.killProcessUnsafe()
+	}
+
+	fmt.Printf("Uruchamianie: %s", m.command)
+	if m.retryCount > 0 {
+		fmt.Printf(" (próba %d/%d)", m.retryCount+1, m.maxRetries)
+	}
+	fmt.Println()
+
+	// Tworzenie komendy do wykonania z kontekstem
+	m.process = exec.CommandContext(m.ctx, "sh", "-c", m.command)
+	m.adopted = false
+
+	files, err := m.launch.apply(m.process)
+	if err != nil {
+		m.retryCount++
+		m.lastFailure = time.Now()
+		return fmt.Errorf("nie można uruchomić procesu (próba %d/%d): %v", m.retryCount, m.maxRetries, err)
+	}
+
+	// NOTIFY_SOCKET, WATCHDOG_USEC i HEARTBEAT_FILE dla aplikacji
+	if m.watchdog != nil {
+		env := m.process.Env
+		if env == nil {
+			env = os.Environ()
+		}
+		env = append([]string(nil), env...)
+		for _, entry := range m.watchdog.env() {
+			env = setEnv(env, entry)
+		}
+		m.process.Env = env
+		m.watchdog.reset()
+	}
+
+	// Uruchomienie procesu w tle
+	err = m.process.Start()
+	for _, f := range files {
+		f.Close() // Proces potomny ma własne kopie deskryptorów
+	}
+	if err != nil {
+		m.retryCount++
+		m.lastFailure = time.Now()
+		return fmt.Errorf("nie można uruchomić procesu (próba %d/%d): %v", m.retryCount, m.maxRetries, err)
+	}
+
+	fmt.Printf("Proces uruchomiony z PID: %d\n", m.process.Process.Pid)
+	m.startedAt = time.Now()
+
+	// Jedyne miejsce wywołujące Wait - zbiera kod wyjścia i zapobiega procesom zombie
+	m.exited = make(chan struct{})
+	go m.reap(m.process, m.exited)
+
+	// Reset metryk - nowy proces = nowy start
+	m.lastModTime = time.Now()
+	m.resetLogGap()
+	// NIE resetuj retry counter tutaj - zrobimy to dopiero po potwierdzeniu że proces działa
+
+	return nil
+}
+
+// Zabija proces - wersja bez locka (używana wewnętrznie)
+func (m *Monitor) killProcessUnsafe() {
+	if m.process == nil || m.process.Process == nil {
+		return
+	}
+
+	pid := m.process.Process.Pid
+	fmt.Printf("Zatrzymywanie procesu PID: %d\n", pid)
+
+	// Wyślij SIGTERM (grzeczne zamknięcie)
+	err := m.process.Process.Signal(syscall.SIGTERM)
+	if err != nil {
+		fmt.Printf("Błąd wysyłania SIGTERM: %v\n", err)
+		return
+	}
+
+	// Uruchom goroutine która czeka na zakończenie procesu
+	done := make(chan error, 1)
+	proc, adopted, exited := m.process, m.adopted, m.exited
+	go func() {
+		if adopted {
+			done <- waitForExit(proc.Process)
+			return
+		}
+		<-exited
+		if code := exitCodeOf(proc); code != 0 {
+			done <- fmt.Errorf("kod wyjścia %d", code)
+			return
+		}
+		done <- nil
+	}()
+
+	// Czekaj maksymalnie stopTimeout na grzeczne zamknięcie
+	select {
+	case err := <-done:
+		if err != nil {
+			fmt.Printf("Proces zakończony z błędem: %v\n", err)
+		} else {
+			fmt.Println("Proces zakończony poprawnie")
+		}
+	case <-time.After(stopTimeout):
+		// Timeout - zabij na siłę
+		fmt.Println("Wymuszanie zakończenia procesu (SIGKILL)...")
+		if m.process.Process != nil {
+			m.process.Process.Kill()
+			// Daj trochę czasu na cleanup, ale nie czekaj w nieskończoność
+			select {
+			case <-done:
+			case <-time.After(2 * time.Second):
+				fmt.Println("Proces może nie zostać prawidłowo zamknięty")
+			}
+		}
+		fmt.Println("Proces zakończony wymuszenie")
+	}
+
+	m.process = nil
+}
+
+// Zabija proces - bezpieczna wersja publiczna
+func (m *Monitor) killProcess() {
+	defer m.saveState()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.killProcessUnsafe()
+}
+
+// Przejmuje proces uruchomiony przez poprzednią instancję monitora
+func (m *Monitor) adoptProcess(proc *os.Process) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.process = &exec.Cmd{Process: proc}
+	m.adopted = true
+	if startedAt, err := procStartedAt(proc.Pid); err == nil {
+		m.startedAt = startedAt
+	}
+}
+
+// Czeka na zakończenie procesu, który nie jest naszym dzieckiem (Wait nie działa)
+func waitForExit(proc *os.Process) error {
+	for proc.Signal(syscall.Signal(0)) == nil {
+		time.Sleep(200 * time.Millisecond)
+	}
+	return nil
+}
+
+// Sprawdza czy proces jeszcze żyje
+func (m *Monitor) isProcessRunning() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.process == nil || m.process.Process == nil {
+		return false
+	}
+
+	// Sprawdź czy proces się już zakończył
+	select {
+	case <-m.ctx.Done():
+		return false
+	default:
+	}
+
+	// Wyślij sygnał 0 - nie zabija procesu, tylko sprawdza czy istnieje
+	err := m.process.Process.Signal(syscall.Signal(0))
+	if err != nil {
+		// Proces nie istnieje, wyczyść referencję
+		m.process = nil
+		return false
+	}
+	return true
+}
+
+
+
+
+
+
+
+
+
+
+
+// Zwraca PID uruchomionego procesu (0 jeśli nie działa)
+func (m *Monitor) pid() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.process == nil || m.process.Process == nil {
+		return 0
+	}
+	return m.process.Process.Pid
+}
+
+// Nazwa używana w komunikatach i powiadomieniach
+func (m *Monitor) displayName() string {
+	if m.name != "" {
+		return m.name
+	}
+	return m.command
+}
+
+// Nazwa procesu (z konfiguracji albo komenda)
+func (m *Monitor) Name() string {
+	return m.displayName()
+}
+
+// Zaplanowany restart; zwraca true, jeśli proces wystartował.
+// Udany restart nie liczy się do limitu prób. Nieudany start zwiększa licznik w startProcess
+// jak każda porażka - proces nie działa, więc dalej restartuje go zwykła pętla.
+// Proces zatrzymany ręcznie (stop) pozostaje zatrzymany do start lub restart.
+func (m *Monitor) scheduledRestart() bool {
+	if m.held {
+		fmt.Printf("Pomijam zaplanowany restart %s - proces zatrzymany ręcznie\n", m.displayName())
+		return false
+	}
+
+	fmt.Println("Restartowanie procesu - powód: zaplanowany restart")
+	if err := m.startProcess(); err != nil {
+		log.Printf("Błąd zaplanowanego restartu: %v", err)
+		return false
+	}
+	fmt.Println("✅ Proces zrestartowany zgodnie z harmonogramem")
+	m.recordRestart("zaplanowany restart")
+	m.notify(eventRestart, "zaplanowany restart", 1)
+	return true
+}
+
+// Kończy pracę monitora: Run zatrzymuje proces, zapisuje stan i wraca
+func (m *Monitor) Shutdown() {
+	m.cancel()
+}
+
+// Wysyła powiadomienie o zdarzeniu tego monitora
+func (m *Monitor) notify(event, reason string, attempt int) {
+	m.notifier.Notify(NotificationEvent{
+		Event:      event,
+		Process:    m.displayName(),
+		Command:    m.command,
+		Reason:     reason,
+		Attempt:    attempt,
+		MaxRetries: m.maxRetries,
+		PID:        m.pid(),
+	})
+}
+
+// Sprawdza czy można jeszcze spróbować restart
+func (m *Monitor) canRetry() bool {
+    return m.retryCount < m.maxRetries
+}
+
+// Resetuje licznik prób (po stabilnym działaniu)
+func (m *Monitor) resetRetries() {
+    if m.retryCount > 0 {
+        fmt.Printf("🔄 Reset licznika prób (było: %d)\n", m.retryCount)
+        m.retryCount = 0
+        m.saveState()
+    }
+}
+
+
+
+
+
+
+
+
+
+
+
+// Sprawdza czy trwa któreś z okien serwisowych; zwraca czas jego zakończenia
+func (m *Monitor) inMaintenanceWindow(now time.Time) (time.Time, bool) {
+	for _, w := range m.maintenanceWindows {
+		if end, ok := w.activeAt(now); ok {
+			return end, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Waliduje parametry i przygotowuje środowisko
+func (m *Monitor) validate() error {
+	if m.logFile == "" {
+		return nil // Życie procesu zgłasza watchdog, nie logi
+	}
+
+	// Sprawdź czy katalog dla pliku logów istnieje
+	logDir := filepath.Dir(m.logFile)
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return fmt.Errorf("nie można utworzyć katalogu dla logów %s: %v", logDir, err)
+	}
+
+	// Sprawdź czy plik logów istnieje (jeśli nie, spróbuj go utworzyć)
+	if _, err := os.Stat(m.logFile); os.IsNotExist(err) {
+		fmt.Printf("Plik logów nie istnieje, tworzę: %s\n", m.logFile)
+		if file, err := os.Create(m.logFile); err != nil {
+			return fmt.Errorf("nie można utworzyć pliku logów: %v", err)
+		} else {
+			file.Close()
+		}
+	}
+
+	return nil
+}
+
+// Główna pętla monitora - wraca po Shutdown, wyczerpaniu prób restartu albo błędzie startu
+func (m *Monitor) Run() error {
+	fmt.Println("Uruchamianie monitora procesów...")
+	if m.logFile != "" {
+		fmt.Printf("Plik logów: %s\n", m.logFile)
+	}
+	fmt.Printf("Timeout: %v\n", m.timeout)
+	if m.adaptive != nil {
+		fmt.Printf("Adaptacyjny timeout: %v-%v, %d. percentyl przerw × %g (po %d przerwach)\n",
+			m.adaptive.min, m.adaptive.max, m.adaptive.percentile, m.adaptive.margin, m.adaptive.minSamples)
+	}
+	fmt.Printf("Interwał sprawdzania: %v\n", m.interval)
+	fmt.Printf("Maksymalna liczba prób restartu: %d\n", m.maxRetries)
+	fmt.Println("--------------------------------------------------")
+
+	// Nie kończ, zanim powiadomienia w toku nie zostaną wysłane
+	defer m.notifier.Wait()
+
+	// Po zakończeniu pętli status jest dostępny bez jej udziału
+	finalState := stateStopped
+	defer func() {
+		m.finalStatus = m.buildStatus(finalState)
+		close(m.finished)
+	}()
+
+	// Walidacja parametrów
+	if err := m.validate(); err != nil {
+		finalState = stateFailed
+		return fmt.Errorf("błąd walidacji: %v", err)
+	}
+
+	// Gniazdo NOTIFY_SOCKET musi istnieć, zanim proces dostanie jego ścieżkę
+	if m.watchdog != nil {
+		m.startWatchdog()
+		defer m.watchdog.close()
+		if m.pid() != 0 {
+			// Przejęty proces zgłosił gotowość poprzedniej instancji - daj mu pełny timeout
+			m.watchdog.adopt(time.Now())
+		}
+	}
+
+	// Uruchom proces po raz pierwszy (chyba że przejęto działający proces)
+	if m.pid() == 0 {
+		if err := m.startProcess(); err != nil {
+			finalState = stateFailed
+			return fmt.Errorf("błąd uruchamiania: %v", err)
+		}
+	}
+
+	// Timer sprawdzający stan co określony interwał
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	// Licznik stabilnych iteracji (do resetu retry counter)
+	stableIterations := 0
+
+	// Timer zaplanowanych restartów (kanał nil blokuje, gdy brak harmonogramu)
+	var scheduleC <-chan time.Time
+	var scheduleTimer *time.Timer
+	if m.restartSchedule != nil {
+		next := m.restartSchedule.Next(time.Now())
+		fmt.Printf("Następny zaplanowany restart: %s\n", next.Format("2006-01-02 15:04"))
+		scheduleTimer = time.NewTimer(time.Until(next))
+		defer scheduleTimer.Stop()
+		scheduleC = scheduleTimer.C
+	}
+
+	// Odczekanie po nieudanym restarcie (kanał nil blokuje, gdy nie czekamy) - w tym
+	// czasie pętla nadal odpowiada na status, polecenia i zakończenie
+	var retryC <-chan time.Time
+	var retryTimer *time.Timer
+	defer func() {
+		if retryTimer != nil {
+			retryTimer.Stop()
+		}
+	}()
+
+	// Główna pętla
+	for {
+		select {
+		case <-m.ctx.Done():
+			// Kontekst został anulowany
+			m.killProcess()
+			fmt.Println("Monitor zakończony przez kontekst")
+			return nil
+
+		case <-scheduleC:
+			if m.scheduledRestart() {
+				stableIterations = 0
+			}
+
+			next := m.restartSchedule.Next(time.Now())
+			fmt.Printf("Następny zaplanowany restart: %s\n", next.Format("2006-01-02 15:04"))
+			scheduleTimer.Reset(time.Until(next))
+
+		case reply := <-m.statusReq:
+			state := stateStopped
+			if m.held {
+				state = stateHeld
+			} else if m.isProcessRunning() {
+				state = stateRunning
+				if m.watchdog != nil && m.watchdog.starting() {
+					state = stateStarting
+				}
+			}
+			reply <- m.buildStatus(state)
+
+		case cmd := <-m.commands:
+			cmd.reply <- m.handleCommand(cmd.action)
+			stableIterations = 0
+			// Ręczne polecenie kończy odczekiwanie - kolejne sprawdzenie oceni nowy stan
+			if retryTimer != nil {
+				retryTimer.Stop()
+				retryC = nil
+			}
+
+		case <-retryC:
+			// Koniec odczekiwania - restart przy najbliższym sprawdzeniu
+			retryC = nil
+
+		case <-ticker.C:
+			// Proces zatrzymany ręcznie albo trwa odczekiwanie po nieudanym restarcie
+			if m.held || retryC != nil {
+				continue
+			}
+
+			// Czas na kolejne sprawdzenie
+			needRestart := false
+			reason := ""
+
+			// 1. Sprawdź czy proces jeszcze żyje
+			if !m.isProcessRunning() {
+				needRestart = true
+				reason = "proces przestał działać"
+				stableIterations = 0
+			}
+
+			// 2. Sygnały od aplikacji: READY=1, WATCHDOG=1, plik heartbeat
+			if !needRestart && m.watchdog != nil {
+				if r := m.watchdog.check(m.startedAt, time.Now()); r != "" {
+					fmt.Printf("WATCHDOG! %s\n", r)
+					needRestart = true
+					reason = r
+					stableIterations = 0
+				}
+			}
+
+			// 3. Sprawdź aktywność w logach (tylko jeśli proces żyje)
+			if !needRestart {
+				logOk := true
+				if m.logFile != "" {
+					ok, err := m.checkLogs()
+					if err != nil {
+						log.Printf("Błąd sprawdzania logów: %v", err)
+						continue
+					}
+					logOk = ok
+				}
+				if !logOk {
+					// W oknie serwisowym cisza w logach jest oczekiwana
+					if end, ok := m.inMaintenanceWindow(time.Now()); ok {
+						fmt.Printf("Okno serwisowe do %s - pomijam restart z powodu braku logów\n",
+							end.Format("15:04:05"))
+						m.lastModTime = time.Now()
+						m.resetLogGap()
+						continue
+					}
+					needRestart = true
+					reason = "brak aktywności w logach"
+					stableIterations = 0
+				} else {
+					stableIterations++
+					// Po 10 stabilnych iteracjach (około 50 sekund z domyślnym interwałem)
+					// resetuj licznik prób
+					if stableIterations >= stableIterationsReset {
+						m.resetRetries()
+						stableIterations = 0
+					}
+				}
+			}
+
+			// 4. Jeśli trzeba, restartuj proces
+			if needRestart {
+				if !m.canRetry() {
+					fmt.Printf("❌ KRYTYCZNY BŁĄD: Przekroczono maksymalną liczbę prób (%d)\n", m.maxRetries)
+					fmt.Printf("Ostatnia nieudana próba: %v\n", m.lastFailure.Format("15:04:05"))
+					fmt.Println("Monitor kończy działanie. Sprawdź konfigurację i uruchom ponownie.")
+					m.notify(eventFailure, reason, m.retryCount)
+					finalState = stateFailed
+					m.cancel()
+					return fmt.Errorf("przekroczono maksymalną liczbę prób restartu (%d)", m.maxRetries)
+				}
+
+				fmt.Printf("Restartowanie procesu - powód: %s", reason)
+				if m.retryCount > 0 {
+					fmt.Printf(" (próba %d/%d)", m.retryCount+1, m.maxRetries)
+				}
+				fmt.Println()
+
+				if err := m.startProcess(); err != nil {
+					log.Printf("Błąd restartu: %v", err)
+					
+					// Jeśli to była ostatnia próba, zakończ
+					if !m.canRetry() {
+						fmt.Printf("❌ Wyczerpano wszystkie próby restartu\n")
+						m.notify(eventFailure, fmt.Sprintf("%s: %v", reason, err), m.retryCount)
+						finalState = stateFailed
+						m.cancel()
+						return fmt.Errorf("wyczerpano próby restartu: %v", err)
+					}
+					
+					// Zwiększ interwał oczekiwania przy kolejnych próbach
+					waitTime := time.Duration(m.retryCount) * time.Second * 5
+					fmt.Printf("Oczekiwanie %v przed kolejną próbą...\n", waitTime)
+					retryTimer = time.NewTimer(waitTime)
+					retryC = retryTimer.C
+					continue
+				}
+
+				fmt.Printf("✅ Proces zrestartowany pomyślnie")
+				if m.retryCount > 0 {
+					fmt.Printf(" (próba %d/%d)", m.retryCount+1, m.maxRetries)
+				}
+				fmt.Println()
+				m.recordRestart(reason)
+				m.notify(eventRestart, reason, m.retryCount+1)
+				stableIterations = 0
+			}
+		}
+	}
+}
+
+// Tworzy monitor na podstawie wpisu z pliku konfiguracyjnego
+func NewMonitorFromConfig(pc config.ProcessConfig, global config.NotificationConfig) (*Monitor, error) {
+	monitor := NewMonitor(pc.Command, pc.LogFile, pc.Timeout, pc.Interval)
+	monitor.name = pc.Name
+
+	launch, err := newLaunchSpec(pc)
+	if err != nil {
+		return nil, err
+	}
+	monitor.launch = launch
+
+	if pc.AdaptiveTimeout != nil {
+		adaptive, err := newAdaptiveTimeout(*pc.AdaptiveTimeout, monitor.timeout, monitor.interval)
+		if err != nil {
+			return nil, fmt.Errorf("adaptive_timeout: %v", err)
+		}
+		monitor.adaptive = adaptive
+	}
+
+	if pc.Watchdog != nil {
+		watchdog, err := newWatchdog(*pc.Watchdog, monitor.interval)
+		if err != nil {
+			return nil, fmt.Errorf("watchdog: %v", err)
+		}
+		monitor.watchdog = watchdog
+	}
+	if pc.LogFile == "" && (monitor.watchdog == nil || monitor.watchdog.timeout == 0) {
+		return nil, fmt.Errorf("log_file jest wymagany, chyba że życie procesu sprawdza watchdog (watchdog.timeout)")
+	}
+
+	if pc.RestartSchedule != "" {
+		schedule, err := parseCron(pc.RestartSchedule)
+		if err != nil {
+			return nil, fmt.Errorf("restart_schedule: %v", err)
+		}
+		monitor.restartSchedule = schedule
+	}
+
+	for i, wc := range pc.MaintenanceWindows {
+		schedule, err := parseCron(wc.Start)
+		if err != nil {
+			return nil, fmt.Errorf("maintenance_windows[%d]: %v", i, err)
+		}
+		if wc.Duration <= 0 {
+			return nil, fmt.Errorf("maintenance_windows[%d]: duration musi być większe od 0", i)
+		}
+		monitor.maintenanceWindows = append(monitor.maintenanceWindows, maintenanceWindow{
+			schedule: schedule,
+			duration: time.Duration(wc.Duration) * time.Second,
+		})
+	}
+
+	notifier, err := newNotifier(mergeNotificationConfig(global, pc.Notifications))
+	if err != nil {
+		return nil, fmt.Errorf("notifications: %v", err)
+	}
+	monitor.notifier = notifier
+
+	return monitor, nil
+}
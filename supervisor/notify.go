@@ -0,0 +1,321 @@
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"monitor_mutex/config"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Typy zdarzeń wysyłanych przez notifier
+const (
+	eventRestart = "restart" // Proces został zrestartowany
+	eventFailure = "failure" // Wyczerpano próby restartu, monitor kończy działanie
+)
+
+// Zdarzenie przekazywane do webhooków i hooków
+type NotificationEvent struct {
+	Event      string    `json:"event"`
+	Process    string    `json:"process"`
+	Command    string    `json:"command"`
+	Reason     string    `json:"reason"`
+	Attempt    int       `json:"attempt"`
+	MaxRetries int       `json:"max_retries"`
+	PID        int       `json:"pid,omitempty"`
+	Host       string    `json:"host"`
+	Time       time.Time `json:"time"`
+	Suppressed int       `json:"suppressed,omitempty"` // Ile identycznych zdarzeń pominięto od ostatniego wysłania
+}
+
+// Domyślne wartości powiadomień
+const (
+	defaultDedupWindow    = 60
+	defaultWebhookRetries = 3
+	defaultWebhookTimeout = 10
+	hookTimeout           = 30 * time.Second
+)
+
+// Łączy konfigurację globalną z konfiguracją procesu
+// (webhooki są sumowane, pozostałe pola procesu nadpisują globalne)
+func mergeNotificationConfig(global config.NotificationConfig, proc *config.NotificationConfig) config.NotificationConfig {
+	if proc == nil {
+		return global
+	}
+
+	merged := global
+	merged.Webhooks = append(append([]config.WebhookConfig{}, global.Webhooks...), proc.Webhooks...)
+	if proc.OnRestart != "" {
+		merged.OnRestart = proc.OnRestart
+	}
+	if proc.OnFailure != "" {
+		merged.OnFailure = proc.OnFailure
+	}
+	if proc.DedupWindow != 0 {
+		merged.DedupWindow = proc.DedupWindow
+	}
+	if proc.MaxPerHour != 0 {
+		merged.MaxPerHour = proc.MaxPerHour
+	}
+	return merged
+}
+
+// Przygotowany webhook ze sparsowanym szablonem
+type webhook struct {
+	config config.WebhookConfig
+	body   *template.Template // nil = domyślny JSON
+	client *http.Client
+}
+
+// Wysyła powiadomienia o zdarzeniach monitora
+type notifier struct {
+	webhooks    []webhook
+	onRestart   string
+	onFailure   string
+	dedupWindow time.Duration
+	maxPerHour  int
+
+	mu         sync.Mutex
+	lastSent   map[string]time.Time // Klucz zdarzenia -> czas ostatniego wysłania
+	suppressed map[string]int       // Klucz zdarzenia -> liczba pominiętych
+	sentTimes  []time.Time          // Czasy wysłań z ostatniej godziny
+	pending    sync.WaitGroup       // Wysyłki w toku
+}
+
+// Funkcje dostępne w szablonach webhooków
+var webhookTemplateFuncs = template.FuncMap{
+	// json koduje wartość jako JSON, np. {"text": {{json .Reason}}}
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// Tworzy notifier; zwraca nil, jeśli nic nie skonfigurowano
+func newNotifier(cfg config.NotificationConfig) (*notifier, error) {
+	if len(cfg.Webhooks) == 0 && cfg.OnRestart == "" && cfg.OnFailure == "" {
+		return nil, nil
+	}
+
+	n := &notifier{
+		onRestart:   cfg.OnRestart,
+		onFailure:   cfg.OnFailure,
+		dedupWindow: time.Duration(cfg.DedupWindow) * time.Second,
+		maxPerHour:  cfg.MaxPerHour,
+		lastSent:    make(map[string]time.Time),
+		suppressed:  make(map[string]int),
+	}
+	if cfg.DedupWindow == 0 {
+		n.dedupWindow = defaultDedupWindow * time.Second
+	}
+
+	for i, wc := range cfg.Webhooks {
+		if wc.URL == "" {
+			return nil, fmt.Errorf("webhooks[%d]: brak url", i)
+		}
+		if wc.Method == "" {
+			wc.Method = http.MethodPost
+		}
+		if wc.Retries == 0 {
+			wc.Retries = defaultWebhookRetries
+		}
+		if wc.Timeout == 0 {
+			wc.Timeout = defaultWebhookTimeout
+		}
+		for _, ev := range wc.Events {
+			if ev != eventRestart && ev != eventFailure {
+				return nil, fmt.Errorf("webhooks[%d]: nieznane zdarzenie %q", i, ev)
+			}
+		}
+
+		wh := webhook{
+			config: wc,
+			client: &http.Client{Timeout: time.Duration(wc.Timeout) * time.Second},
+		}
+		if wc.Body != "" {
+			tmpl, err := template.New(wc.URL).Funcs(webhookTemplateFuncs).Parse(wc.Body)
+			if err != nil {
+				return nil, fmt.Errorf("webhooks[%d]: błąd szablonu body: %v", i, err)
+			}
+			wh.body = tmpl
+		}
+		n.webhooks = append(n.webhooks, wh)
+	}
+
+	return n, nil
+}
+
+// Decyduje czy zdarzenie wysłać (deduplikacja i limit godzinowy)
+func (n *notifier) allow(ev *NotificationEvent) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := ev.Time
+	key := ev.Event + "|" + ev.Process + "|" + ev.Reason
+
+	if last, ok := n.lastSent[key]; ok && now.Sub(last) < n.dedupWindow {
+		n.suppressed[key]++
+		return false
+	}
+
+	// Usuń wysyłki starsze niż godzina
+	recent := n.sentTimes[:0]
+	for _, t := range n.sentTimes {
+		if now.Sub(t) < time.Hour {
+			recent = append(recent, t)
+		}
+	}
+	n.sentTimes = recent
+
+	if n.maxPerHour > 0 && len(n.sentTimes) >= n.maxPerHour {
+		n.suppressed[key]++
+		return false
+	}
+
+	n.lastSent[key] = now
+	n.sentTimes = append(n.sentTimes, now)
+	ev.Suppressed = n.suppressed[key]
+	delete(n.suppressed, key)
+	return true
+}
+
+// Wysyła zdarzenie w tle (bezpieczne dla nil)
+func (n *notifier) Notify(ev NotificationEvent) {
+	if n == nil {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	if ev.Host == "" {
+		ev.Host, _ = os.Hostname()
+	}
+
+	if !n.allow(&ev) {
+		fmt.Printf("Powiadomienie %s dla %s pominięte (limit/duplikat)\n", ev.Event, ev.Process)
+		return
+	}
+
+	for _, wh := range n.webhooks {
+		if !wh.wants(ev.Event) {
+			continue
+		}
+		n.pending.Add(1)
+		go func(wh webhook) {
+			defer n.pending.Done()
+			if err := wh.send(ev); err != nil {
+				log.Printf("Błąd webhooka %s: %v", wh.config.URL, err)
+			}
+		}(wh)
+	}
+
+	hook := n.onRestart
+	if ev.Event == eventFailure {
+		hook = n.onFailure
+	}
+	if hook != "" {
+		n.pending.Add(1)
+		go func() {
+			defer n.pending.Done()
+			if err := runHook(hook, ev); err != nil {
+				log.Printf("Błąd hooka on_%s: %v", ev.Event, err)
+			}
+		}()
+	}
+}
+
+// Czeka na zakończenie wysyłek w toku (bezpieczne dla nil)
+func (n *notifier) Wait() {
+	if n == nil {
+		return
+	}
+	n.pending.Wait()
+}
+
+// Sprawdza czy webhook subskrybuje dane zdarzenie
+func (wh webhook) wants(event string) bool {
+	if len(wh.config.Events) == 0 {
+		return true
+	}
+	for _, e := range wh.config.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Wysyła żądanie z ponowieniami (opóźnienie rośnie liniowo)
+func (wh webhook) send(ev NotificationEvent) error {
+	var body bytes.Buffer
+	if wh.body != nil {
+		if err := wh.body.Execute(&body, ev); err != nil {
+			return fmt.Errorf("błąd szablonu: %v", err)
+		}
+	} else if err := json.NewEncoder(&body).Encode(ev); err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= wh.config.Retries; attempt++ {
+		req, err := http.NewRequest(wh.config.Method, wh.config.URL, bytes.NewReader(body.Bytes()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range wh.config.Headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := wh.client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 300 {
+				return nil
+			}
+			err = fmt.Errorf("odpowiedź HTTP %d", resp.StatusCode)
+		}
+		lastErr = err
+
+		if attempt < wh.config.Retries {
+			time.Sleep(time.Duration(attempt) * 2 * time.Second)
+		}
+	}
+
+	return fmt.Errorf("po %d próbach: %v", wh.config.Retries, lastErr)
+}
+
+// Uruchamia hook shell ze szczegółami zdarzenia w zmiennych środowiskowych
+func runHook(command string, ev NotificationEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"MONITOR_EVENT="+ev.Event,
+		"MONITOR_PROCESS="+ev.Process,
+		"MONITOR_COMMAND="+ev.Command,
+		"MONITOR_REASON="+ev.Reason,
+		"MONITOR_ATTEMPT="+strconv.Itoa(ev.Attempt),
+		"MONITOR_MAX_RETRIES="+strconv.Itoa(ev.MaxRetries),
+		"MONITOR_PID="+strconv.Itoa(ev.PID),
+		"MONITOR_HOST="+ev.Host,
+		"MONITOR_TIME="+ev.Time.Format(time.RFC3339),
+		"MONITOR_SUPPRESSED="+strconv.Itoa(ev.Suppressed),
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"monitor_mutex/config"
+	"monitor_mutex/procfs"
+	"os"
+	"sync"
+	"time"
+)
+
+// Domyślna nazwa pliku stanu (obok pliku konfiguracyjnego)
+const defaultStateFileName = "monitor_state.json"
+
+// Ile ostatnich restartów przechowywać dla każdego procesu
+const maxRestartHistory = 100
+
+// Wersja formatu pliku stanu
+const stateFileVersion = 1
+
+// Pojedynczy restart zapisany w historii
+type restartEvent struct {
+	Time   time.Time `json:"time"`
+	Reason string    `json:"reason"`
+}
+
+// Stan procesu przetrwający restart monitora
+type processState struct {
+	RetryCount   int            `json:"retry_count"`
+	LastFailure  time.Time      `json:"last_failure"`
+	PID          int            `json:"pid,omitempty"`            // PID działającego procesu (0 = brak)
+	PIDStartTime uint64         `json:"pid_start_time,omitempty"` // Czas startu z /proc/<pid>/stat - ochrona przed ponownym użyciem PID
+	Command      string         `json:"command,omitempty"`        // Komenda z konfiguracji, którą uruchomiono proces
+	Restarts     []restartEvent `json:"restarts,omitempty"`
+	LogGaps      []float64      `json:"log_gaps,omitempty"` // Historia przerw w logach (s) dla adaptive_timeout
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// Zawartość pliku stanu
+type stateFile struct {
+	Version   int                     `json:"version"`
+	Processes map[string]processState `json:"processes"`
+}
+
+// Plik stanu współdzielony przez wszystkie monitory
+type stateStore struct {
+	path string
+	mu   sync.Mutex
+	data stateFile
+}
+
+// Otwiera plik stanu; brak pliku oznacza pusty stan
+func openStateStore(path string) (*stateStore, error) {
+	s := &stateStore{
+		path: path,
+		data: stateFile{Version: stateFileVersion, Processes: make(map[string]processState)},
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("nie można odczytać pliku stanu %s: %v", path, err)
+	}
+
+	var loaded stateFile
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return s, fmt.Errorf("uszkodzony plik stanu %s: %v", path, err)
+	}
+	if loaded.Version != stateFileVersion {
+		return s, fmt.Errorf("nieobsługiwana wersja pliku stanu %s: %d", path, loaded.Version)
+	}
+	if loaded.Processes != nil {
+		s.data.Processes = loaded.Processes
+	}
+
+	return s, nil
+}
+
+// Zwraca zapisany stan procesu
+func (s *stateStore) get(name string) (processState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.data.Processes[name]
+	return st, ok
+}
+
+// Aktualizuje stan procesu i zapisuje cały plik
+func (s *stateStore) put(name string, st processState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st.UpdatedAt = time.Now()
+	s.data.Processes[name] = st
+
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return config.WriteFileAtomic(s.path, data, 0644)
+}
+
+// Odczytuje czas startu procesu (pole 22 z /proc/<pid>/stat, w taktach zegara)
+func procStartTime(pid int) (uint64, error) {
+	st, err := procfs.ReadStat(pid)
+	if err != nil {
+		return 0, err
+	}
+	return st.StartTime, nil
+}
+
+// Sprawdza czy proces o danym PID to nadal ten sam proces uruchomiony przez monitor
+//
+// PID z czasem startu jednoznacznie wskazuje proces. Linia komend nie jest porównywana:
+// sh -c wykonuje prostą komendę przez exec, więc cmdline traci cudzysłowy i przekierowania.
+func isSameProcess(pid int, startTime uint64) bool {
+	current, err := procStartTime(pid)
+	return err == nil && current == startTime
+}
+
+// Buduje stan do zapisu na podstawie bieżących pól monitora
+func (m *Monitor) snapshotState() processState {
+	st := processState{
+		RetryCount:  m.retryCount,
+		LastFailure: m.lastFailure,
+		Restarts:    m.restarts.all(),
+	}
+	if m.adaptive != nil {
+		st.LogGaps = m.adaptive.export()
+	}
+	if pid := m.pid(); pid > 0 {
+		if startTime, err := procStartTime(pid); err == nil {
+			st.PID = pid
+			st.PIDStartTime = startTime
+			st.Command = m.command
+		}
+	}
+	return st
+}
+
+// Zapisuje stan monitora (jeśli plik stanu jest włączony)
+func (m *Monitor) saveState() {
+	if m.state == nil {
+		return
+	}
+	if err := m.state.put(m.displayName(), m.snapshotState()); err != nil {
+		log.Printf("Błąd zapisu stanu %s: %v", m.displayName(), err)
+	}
+}
+
+// Dopisuje restart do historii i zapisuje stan
+func (m *Monitor) recordRestart(reason string) {
+	m.restarts.add(restartEvent{Time: time.Now(), Reason: reason})
+	m.saveState()
+}
+
+// Przywraca stan zapisany przez poprzednią instancję monitora
+// i przejmuje proces, jeśli nadal działa
+func (m *Monitor) restoreState(store *stateStore) {
+	m.state = store
+
+	st, ok := store.get(m.displayName())
+	if !ok {
+		return
+	}
+
+	m.retryCount = st.RetryCount
+	m.lastFailure = st.LastFailure
+	for _, ev := range st.Restarts {
+		m.restarts.add(ev)
+	}
+	if m.adaptive != nil {
+		m.adaptive.load(st.LogGaps)
+	}
+	if m.retryCount > 0 {
+		fmt.Printf("Przywrócono stan %s: próby %d/%d\n", m.displayName(), m.retryCount, m.maxRetries)
+	}
+
+	if st.PID <= 0 || !isSameProcess(st.PID, st.PIDStartTime) {
+		return
+	}
+	proc, err := os.FindProcess(st.PID)
+	if err != nil {
+		return
+	}
+	m.adoptProcess(proc)
+
+	// Proces z poprzednią komendą zatrzymujemy - pozostawiony działałby obok nowej kopii
+	if st.Command != "" && st.Command != m.command {
+		fmt.Printf("Komenda %s zmieniła się od uruchomienia procesu PID %d - zatrzymuję stary proces\n", m.displayName(), st.PID)
+		m.killProcess()
+		return
+	}
+	fmt.Printf("Przejęto działający proces %s (PID: %d)\n", m.displayName(), st.PID)
+}
@@ -0,0 +1,310 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// Stany procesu widoczne w statusie
+const (
+	stateRunning  = "running"  // Proces działa
+	stateStopped  = "stopped"  // Proces nie działa (czeka na restart lub monitor zatrzymany)
+	stateFailed   = "failed"   // Wyczerpano próby restartu, monitor zakończył działanie
+	stateBusy     = "busy"     // Monitor nie odpowiedział (np. trwa zatrzymywanie procesu)
+	stateHeld     = "held"     // Proces zatrzymany ręcznie (stop), czeka na start
+	stateStarting = "starting" // Proces działa, ale jeszcze nie wysłał READY=1
+)
+
+// Jak długo czekać na odpowiedź pętli monitora
+const statusReplyTimeout = 2 * time.Second
+
+// Bufor cykliczny ostatnich restartów
+type restartRing struct {
+	events []restartEvent
+	next   int  // Indeks następnego zapisu
+	full   bool // Bufor został zapełniony co najmniej raz
+}
+
+// Tworzy bufor o podanej pojemności
+func newRestartRing(capacity int) *restartRing {
+	return &restartRing{events: make([]restartEvent, capacity)}
+}
+
+// Dodaje zdarzenie, nadpisując najstarsze po zapełnieniu
+func (r *restartRing) add(ev restartEvent) {
+	r.events[r.next] = ev
+	r.next = (r.next + 1) % len(r.events)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Zwraca zdarzenia od najstarszego do najnowszego
+func (r *restartRing) all() []restartEvent {
+	if !r.full {
+		return append([]restartEvent(nil), r.events[:r.next]...)
+	}
+	return append(append([]restartEvent(nil), r.events[r.next:]...), r.events[:r.next]...)
+}
+
+// Zwraca najnowsze zdarzenie
+func (r *restartRing) last() (restartEvent, bool) {
+	if !r.full && r.next == 0 {
+		return restartEvent{}, false
+	}
+	return r.events[(r.next-1+len(r.events))%len(r.events)], true
+}
+
+// Liczy restarty nowsze niż podany czas
+func (r *restartRing) countSince(since time.Time) int {
+	count := 0
+	for _, ev := range r.all() {
+		if ev.Time.After(since) {
+			count++
+		}
+	}
+	return count
+}
+
+// Status procesu zwracany przez `monitor_mutex status`
+type ProcessStatus struct {
+	Name              string          `json:"name"`
+	State             string          `json:"state"`
+	PID               int             `json:"pid,omitempty"`
+	UptimeSeconds     int64           `json:"uptime_seconds"`
+	Restarts1h        int             `json:"restarts_1h"`
+	Restarts24h       int             `json:"restarts_24h"`
+	LastRestart       *time.Time      `json:"last_restart,omitempty"`
+	LastRestartReason string          `json:"last_restart_reason,omitempty"`
+	LastExitCode      *int            `json:"last_exit_code,omitempty"`
+	LogIdleSeconds    int64           `json:"log_idle_seconds"`
+	TimeoutSeconds    int64           `json:"timeout_seconds"`
+	Adaptive          *AdaptiveStatus `json:"adaptive_timeout,omitempty"`
+	Watchdog          *WatchdogStatus `json:"watchdog,omitempty"`
+	RetryCount        int             `json:"retry_count"`
+	MaxRetries        int             `json:"max_retries"`
+}
+
+// Sygnały od aplikacji (tylko z watchdog)
+type WatchdogStatus struct {
+	Ready           bool   `json:"ready"`
+	Status          string `json:"status,omitempty"`  // Ostatni STATUS= od aplikacji
+	LastPingSeconds int64  `json:"last_ping_seconds"` // Sekundy od ostatniego sygnału życia (-1 = jeszcze żadnego)
+}
+
+// Wyuczony rozkład przerw w logach (tylko z adaptive_timeout)
+type AdaptiveStatus struct {
+	Samples              int     `json:"samples"`
+	MinSamples           int     `json:"min_samples"`
+	Learning             bool    `json:"learning"` // Za mało przerw - obowiązuje stały timeout
+	MedianGapSeconds     float64 `json:"median_gap_seconds"`
+	Percentile           int     `json:"percentile"`
+	PercentileGapSeconds float64 `json:"percentile_gap_seconds"`
+	MinSeconds           int64   `json:"min_seconds"`
+	MaxSeconds           int64   `json:"max_seconds"`
+}
+
+// Zwraca kod wyjścia zakończonego procesu (128+sygnał, gdy zabity sygnałem)
+func exitCodeOf(cmd *exec.Cmd) int {
+	if cmd.ProcessState == nil {
+		return -1
+	}
+	if ws, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal())
+	}
+	return cmd.ProcessState.ExitCode()
+}
+
+// Czeka na zakończenie naszego procesu potomnego i zapamiętuje kod wyjścia
+func (m *Monitor) reap(cmd *exec.Cmd, exited chan struct{}) {
+	cmd.Wait()
+	code := exitCodeOf(cmd)
+
+	m.exitMu.Lock()
+	m.lastExitCode = &code
+	m.exitMu.Unlock()
+
+	close(exited)
+}
+
+// Odczytuje czas uruchomienia dowolnego procesu z /proc (dla procesów przejętych)
+func procStartedAt(pid int) (time.Time, error) {
+	ticks, err := procStartTime(pid)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "btime ") {
+			btime, err := strconv.ParseInt(strings.TrimSpace(line[6:]), 10, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			// /proc eksportuje czasy w USER_HZ, które w Linuksie wynosi 100
+			return time.Unix(btime, 0).Add(time.Duration(ticks) * 10 * time.Millisecond), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("brak btime w /proc/stat")
+}
+
+// Buduje status - wywoływane wyłącznie z pętli Run, więc bez dodatkowych locków
+func (m *Monitor) buildStatus(state string) ProcessStatus {
+	now := time.Now()
+	st := ProcessStatus{
+		Name:        m.displayName(),
+		State:       state,
+		PID:         m.pid(),
+		Restarts1h:  m.restarts.countSince(now.Add(-time.Hour)),
+		Restarts24h: m.restarts.countSince(now.Add(-24 * time.Hour)),
+		RetryCount:  m.retryCount,
+		MaxRetries:  m.maxRetries,
+
+		TimeoutSeconds: int64(m.effectiveTimeout().Seconds()),
+	}
+
+	if m.watchdog != nil {
+		st.Watchdog = m.watchdog.snapshot(now)
+	}
+
+	if a := m.adaptive; a != nil {
+		_, learned := a.learned()
+		st.Adaptive = &AdaptiveStatus{
+			Samples:              len(a.samples()),
+			MinSamples:           a.minSamples,
+			Learning:             !learned,
+			MedianGapSeconds:     a.gapPercentile(50).Seconds(),
+			Percentile:           a.percentile,
+			PercentileGapSeconds: a.gapPercentile(a.percentile).Seconds(),
+			MinSeconds:           int64(a.min.Seconds()),
+			MaxSeconds:           int64(a.max.Seconds()),
+		}
+	}
+
+	if st.PID > 0 && !m.startedAt.IsZero() {
+		st.UptimeSeconds = int64(now.Sub(m.startedAt).Seconds())
+	}
+	if !m.lastModTime.IsZero() {
+		st.LogIdleSeconds = int64(now.Sub(m.lastModTime).Seconds())
+	}
+	if ev, ok := m.restarts.last(); ok {
+		st.LastRestart = &ev.Time
+		st.LastRestartReason = ev.Reason
+	}
+
+	m.exitMu.Lock()
+	if m.lastExitCode != nil {
+		code := *m.lastExitCode
+		st.LastExitCode = &code
+	}
+	m.exitMu.Unlock()
+
+	return st
+}
+
+// Zwraca bieżący status monitora (bezpieczne z dowolnej goroutine)
+func (m *Monitor) Status() ProcessStatus {
+	reply := make(chan ProcessStatus, 1)
+
+	select {
+	case m.statusReq <- reply:
+		return <-reply
+	case <-m.finished:
+		return m.finalStatus
+	case <-time.After(statusReplyTimeout):
+		return ProcessStatus{Name: m.displayName(), State: stateBusy, MaxRetries: m.maxRetries}
+	}
+}
+
+// Statusy monitorów w podanej kolejności. Monitory są pytane równolegle, więc zajęty
+// monitor opóźnia całość najwyżej o statusReplyTimeout, a nie o tyle razy, ile ich jest.
+func collectStatuses(monitors []*Monitor) []ProcessStatus {
+	statuses := make([]ProcessStatus, len(monitors))
+	var wg sync.WaitGroup
+	for i, m := range monitors {
+		wg.Add(1)
+		go func(i int, m *Monitor) {
+			defer wg.Done()
+			statuses[i] = m.Status()
+		}(i, m)
+	}
+	wg.Wait()
+	return statuses
+}
+
+// Formatuje czas trwania w krótkiej postaci (np. 3d4h, 2h15m, 45s)
+func formatDuration(seconds int64) string {
+	d := time.Duration(seconds) * time.Second
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm%ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+}
+
+// Wypisuje statusy jako tabelę
+func PrintStatusTable(w io.Writer, statuses []ProcessStatus) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAZWA\tSTAN\tPID\tUPTIME\tRESTARTY 1h/24h\tPRÓBY\tOSTATNI RESTART\tKOD WYJŚCIA\tCISZA W LOGACH\tTIMEOUT")
+
+	for _, st := range statuses {
+		pid, uptime := "-", "-"
+		if st.PID > 0 {
+			pid = strconv.Itoa(st.PID)
+			uptime = formatDuration(st.UptimeSeconds)
+		}
+
+		lastRestart := "-"
+		if st.LastRestart != nil {
+			lastRestart = fmt.Sprintf("%s (%s)", st.LastRestart.Format("01-02 15:04:05"), st.LastRestartReason)
+		}
+
+		exitCode := "-"
+		if st.LastExitCode != nil {
+			exitCode = strconv.Itoa(*st.LastExitCode)
+		}
+
+		// STATUS= od aplikacji mówi więcej niż sam stan
+		state := st.State
+		if st.Watchdog != nil && st.Watchdog.Status != "" {
+			state += " (" + st.Watchdog.Status + ")"
+		}
+
+		timeout := fmt.Sprintf("%ds", st.TimeoutSeconds)
+		if a := st.Adaptive; a != nil && a.Learning {
+			timeout += fmt.Sprintf(" (nauka %d/%d)", a.Samples, a.MinSamples)
+		} else if a != nil {
+			timeout += fmt.Sprintf(" (wyuczony, p%d %.0fs)", a.Percentile, a.PercentileGapSeconds)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d/%d\t%d/%d\t%s\t%s\t%ds\t%s\n",
+			st.Name, state, pid, uptime, st.Restarts1h, st.Restarts24h,
+			st.RetryCount, st.MaxRetries, lastRestart, exitCode, st.LogIdleSeconds, timeout)
+	}
+
+	tw.Flush()
+}
+
+// Wypisuje statusy jako JSON
+func PrintStatusJSON(w io.Writer, statuses []ProcessStatus) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(statuses)
+}
@@ -0,0 +1,265 @@
+// Pakiet supervisor pilnuje procesów na podstawie aktywności w ich plikach logów
+// i restartuje je, gdy logi milkną. Może działać jako osobny program (cmd/monitor)
+// albo zostać osadzony w innej usłudze Go.
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"monitor_mutex/config"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Supervisor uruchamia monitory wszystkich procesów z jednej konfiguracji
+type Supervisor struct {
+	controlSocket string
+	store         *stateStore
+	global        config.NotificationConfig
+
+	mu      sync.Mutex
+	groups  []*processGroup // Wpisy konfiguracji w kolejności z pliku
+	running bool            // Run działa - nowe monitory startują od razu
+	active  int             // Monitory, których Run jeszcze nie wrócił
+	idle    chan struct{}   // Zamykany, gdy active spadnie do 0
+}
+
+// Wpis konfiguracji i jego monitory (wpis z instances ma ich kilka)
+type processGroup struct {
+	config   config.ProcessConfig
+	monitors []*Monitor
+}
+
+// Tworzy monitory dla konfiguracji i przywraca ich stan z pliku stanu.
+// configFile wyznacza domyślne położenie pliku stanu i gniazda sterującego.
+func New(configFile string, cfg *config.Config) (*Supervisor, error) {
+	if len(cfg.Processes) == 0 {
+		return nil, fmt.Errorf("brak procesów do monitorowania w konfiguracji")
+	}
+
+	// Trwały stan - liczniki prób, historia restartów i PID-y do przejęcia
+	stateFile := cfg.StateFile
+	if stateFile == "" {
+		stateFile = filepath.Join(filepath.Dir(configFile), defaultStateFileName)
+	}
+	store, err := openStateStore(stateFile)
+	if err != nil {
+		log.Printf("Ostrzeżenie: %v - zaczynam z pustym stanem", err)
+	}
+
+	s := &Supervisor{
+		controlSocket: ControlSocketPath(configFile, cfg),
+		store:         store,
+		global:        cfg.Notifications,
+	}
+
+	// Utwórz monitory przed startem, żeby błędy konfiguracji wyszły od razu
+	names := make(map[string]bool)
+	for _, pc := range cfg.Processes {
+		instances, err := config.ExpandInstances(pc)
+		if err != nil {
+			return nil, fmt.Errorf("błąd konfiguracji procesu %s: %v", pc.Name, err)
+		}
+		group := &processGroup{config: pc}
+		for _, instance := range instances {
+			if names[instance.Name] {
+				// Status, sterowanie i plik stanu rozpoznają procesy po nazwie
+				return nil, fmt.Errorf("powtórzona nazwa procesu %s - nadaj wpisom unikalne nazwy", instance.Name)
+			}
+			names[instance.Name] = true
+
+			monitor, err := s.newMonitor(instance)
+			if err != nil {
+				return nil, err
+			}
+			group.monitors = append(group.monitors, monitor)
+		}
+		s.groups = append(s.groups, group)
+	}
+
+	return s, nil
+}
+
+// Tworzy monitor jednego procesu i przywraca jego stan
+func (s *Supervisor) newMonitor(pc config.ProcessConfig) (*Monitor, error) {
+	monitor, err := NewMonitorFromConfig(pc, s.global)
+	if err != nil {
+		return nil, fmt.Errorf("błąd konfiguracji procesu %s: %v", pc.Name, err)
+	}
+	// Gniazdo notify obok sterującego - przejęty proces znajdzie je po restarcie monitora
+	if monitor.watchdog != nil {
+		monitor.watchdog.socketPath = notifySocketPath(filepath.Dir(s.controlSocket), pc.Name)
+	}
+	monitor.restoreState(s.store)
+	return monitor, nil
+}
+
+// Monitory poszczególnych procesów (w kolejności z konfiguracji, kopie po kolei)
+func (s *Supervisor) Monitors() []*Monitor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.allMonitors()
+}
+
+// Monitory wszystkich wpisów - wywoływane pod s.mu
+func (s *Supervisor) allMonitors() []*Monitor {
+	var monitors []*Monitor
+	for _, group := range s.groups {
+		monitors = append(monitors, group.monitors...)
+	}
+	return monitors
+}
+
+// Uruchamia gniazdo sterujące i wszystkie monitory; wraca, gdy wszystkie monitory się zakończą.
+// Anulowanie ctx zatrzymuje monitory - procesy są zatrzymywane, a stan zapisywany.
+func (s *Supervisor) Run(ctx context.Context) error {
+	// Gniazdo sterujące dla `monitor_mutex status`
+	control, err := startControlServer(s.controlSocket, s)
+	if err != nil {
+		return err
+	}
+	defer control.Close()
+
+	// Uruchom monitory dla każdego procesu
+	s.mu.Lock()
+	s.running = true
+	s.idle = make(chan struct{})
+	for _, group := range s.groups {
+		for _, monitor := range group.monitors {
+			s.startMonitor(monitor)
+		}
+	}
+	idle := s.idle
+	s.mu.Unlock()
+
+	select {
+	case <-idle:
+	case <-ctx.Done():
+		s.Shutdown()
+		<-idle
+	}
+	return nil
+}
+
+// Uruchamia pętlę monitora w tle - wywoływane pod s.mu
+func (s *Supervisor) startMonitor(monitor *Monitor) {
+	s.active++
+	go func() {
+		fmt.Printf("Uruchamianie monitora dla: %s\n", monitor.Name())
+		if err := monitor.Run(); err != nil {
+			log.Printf("Monitor %s: %v", monitor.Name(), err)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.active--
+		if s.active == 0 {
+			s.running = false
+			close(s.idle)
+		}
+	}()
+}
+
+// Zmienia liczbę kopii wpisu z instances w działającym monitorze.
+// name to nazwa wpisu z konfiguracji albo dowolnej z jego kopii.
+// Nadmiarowe kopie (od najwyższego numeru) są zatrzymywane, nowe startują od razu.
+// Zmiana obowiązuje do restartu monitora - na stałe trzeba zmienić instances w konfiguracji.
+func (s *Supervisor) Scale(name string, count int) ([]*Monitor, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("liczba kopii nie może być ujemna")
+	}
+
+	s.mu.Lock()
+	group := s.findGroup(name)
+	if group == nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("nieznany proces %q", name)
+	}
+	if group.config.Instances == 0 {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("proces %s nie ma instances w konfiguracji", group.config.Name)
+	}
+	if !s.running {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("monitor nie działa")
+	}
+
+	var removed []*Monitor
+	if count < len(group.monitors) {
+		removed = group.monitors[count:]
+		stopping := 0
+		for _, m := range removed {
+			select {
+			case <-m.finished:
+			default:
+				stopping++
+			}
+		}
+		// Bez działających monitorów Run by wrócił i zakończył cały program
+		if stopping == s.active {
+			s.mu.Unlock()
+			return nil, fmt.Errorf("nie można zatrzymać wszystkich monitorów - zakończ program sygnałem")
+		}
+		group.monitors = group.monitors[:count:count]
+	}
+
+	for i := len(group.monitors); i < count; i++ {
+		pc, err := config.ExpandInstance(group.config, i)
+		if err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+		monitor, err := s.newMonitor(pc)
+		if err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+		group.monitors = append(group.monitors, monitor)
+		s.startMonitor(monitor)
+	}
+	monitors := append([]*Monitor(nil), group.monitors...)
+	s.mu.Unlock()
+
+	// Zatrzymanie procesu trwa do stopTimeout - odpowiedz, gdy nadmiarowe kopie skończą
+	for _, m := range removed {
+		m.Shutdown()
+	}
+	deadline := time.After(stopTimeout + time.Second)
+	for _, m := range removed {
+		select {
+		case <-m.finished:
+		case <-deadline:
+			return monitors, nil
+		}
+	}
+	return monitors, nil
+}
+
+// Szuka wpisu po nazwie z konfiguracji albo nazwie kopii - wywoływane pod s.mu
+func (s *Supervisor) findGroup(name string) *processGroup {
+	for _, group := range s.groups {
+		if group.config.Name == name {
+			return group
+		}
+		for _, m := range group.monitors {
+			if m.displayName() == name {
+				return group
+			}
+		}
+	}
+	return nil
+}
+
+// Zatrzymuje wszystkie monitory; Scale nie dodaje już nowych
+func (s *Supervisor) Shutdown() {
+	s.mu.Lock()
+	s.running = false
+	monitors := s.allMonitors()
+	s.mu.Unlock()
+
+	for _, monitor := range monitors {
+		monitor.Shutdown()
+	}
+}
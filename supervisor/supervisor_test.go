@@ -0,0 +1,1164 @@
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"monitor_mutex/config"
+)
+
+// Czeka, aż warunek zostanie spełniony albo minie limit czasu
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("przekroczono czas oczekiwania: %s", what)
+}
+
+// Osadzony Supervisor uruchamia proces, udostępnia status przez gniazdo i kończy pracę po anulowaniu kontekstu
+func TestSupervisorEmbedded(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "app.log")
+	configFile := filepath.Join(dir, "monitor_config.yaml")
+
+	cfg := &config.Config{
+		Processes: []config.ProcessConfig{{
+			Name:     "app",
+			Command:  "while true; do echo tick >> " + logFile + "; sleep 0.2; done",
+			LogFile:  logFile,
+			Timeout:  30,
+			Interval: 1,
+		}},
+	}
+
+	sup, err := New(configFile, cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	monitor := sup.Monitors()[0]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- sup.Run(ctx) }()
+
+	waitFor(t, "proces app działa", func() bool {
+		st := monitor.Status()
+		return st.State == stateRunning && st.PID > 0
+	})
+
+	socket := ControlSocketPath(configFile, cfg)
+	waitFor(t, "gniazdo sterujące odpowiada", func() bool {
+		statuses, err := SendCommand(socket, "status", "")
+		return err == nil && len(statuses) == 1 && statuses[0].Name == "app"
+	})
+
+	if err := monitor.Control(ActionStop); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if st := monitor.Status(); st.State != stateHeld || st.PID != 0 {
+		t.Errorf("po stop oczekiwano stanu %s bez PID, jest %s (PID %d)", stateHeld, st.State, st.PID)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run nie zakończył się po anulowaniu kontekstu")
+	}
+
+	if st := monitor.Status(); st.State != stateStopped {
+		t.Errorf("po zakończeniu oczekiwano stanu %s, jest %s", stateStopped, st.State)
+	}
+}
+
+// Zaplanowany restart nie uruchamia procesu zatrzymanego ręcznie
+func TestScheduledRestartSkipsHeld(t *testing.T) {
+	m := NewMonitor("sleep 30", filepath.Join(t.TempDir(), "app.log"), 30, 1)
+	defer m.killProcess()
+
+	m.held = true
+	if m.scheduledRestart() || m.pid() != 0 {
+		t.Fatalf("zaplanowany restart uruchomił zatrzymany proces (PID %d)", m.pid())
+	}
+	if m.retryCount != 0 || len(m.restarts.all()) != 0 {
+		t.Errorf("pominięty restart zmienił liczniki: próby %d, historia %d", m.retryCount, len(m.restarts.all()))
+	}
+
+	m.held = false
+	if !m.scheduledRestart() || m.pid() == 0 {
+		t.Fatal("zaplanowany restart nie uruchomił procesu")
+	}
+	if m.retryCount != 0 || len(m.restarts.all()) != 1 {
+		t.Errorf("po restarcie: próby %d, historia %d", m.retryCount, len(m.restarts.all()))
+	}
+}
+
+// Odczekiwanie po nieudanym restarcie nie blokuje statusu, poleceń ani zakończenia
+func TestRetryBackoffKeepsLoopResponsive(t *testing.T) {
+	dir := t.TempDir()
+	outDir := filepath.Join(dir, "out")
+	if err := os.Mkdir(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Proces szybko się kończy, a bez katalogu out kolejne uruchomienie się nie udaje
+	m, err := NewMonitorFromConfig(config.ProcessConfig{
+		Name:     "flaky",
+		Command:  "sleep 0.3",
+		LogFile:  filepath.Join(dir, "app.log"),
+		Stdout:   filepath.Join(outDir, "stdout.log"),
+		Timeout:  30,
+		Interval: 1,
+	}, config.NotificationConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- m.Run() }()
+	defer m.Shutdown()
+
+	waitFor(t, "monitor uruchamia proces", func() bool { return m.Status().PID > 0 })
+	if err := os.RemoveAll(outDir); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "nieudany restart", func() bool { return m.Status().RetryCount > 0 })
+
+	// Monitor czeka teraz 5 s przed kolejną próbą
+	start := time.Now()
+	if st := m.Status(); st.State != stateStopped || time.Since(start) > time.Second {
+		t.Errorf("status w trakcie odczekiwania: %s po %v", st.State, time.Since(start))
+	}
+	if err := os.Mkdir(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Control(ActionRestart); err != nil {
+		t.Fatalf("restart w trakcie odczekiwania: %v", err)
+	}
+	if st := m.Status(); st.State != stateRunning {
+		t.Errorf("po ręcznym restarcie: %s", st.State)
+	}
+
+	start = time.Now()
+	m.Shutdown()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Run nie zakończył się po Shutdown")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Shutdown trwał %v", elapsed)
+	}
+}
+
+// Zajęte monitory nie blokują statusu po kolei - dashboard i status czekają najwyżej raz
+func TestCollectStatusesParallel(t *testing.T) {
+	var monitors []*Monitor
+	for i := 0; i < 3; i++ {
+		// Monitor bez uruchomionej pętli Run nie odpowiada, jak zajęty zatrzymywaniem procesu
+		monitors = append(monitors, NewMonitor("true", "/tmp/app.log", 30, 1))
+	}
+
+	start := time.Now()
+	statuses := collectStatuses(monitors)
+	if elapsed := time.Since(start); elapsed > statusReplyTimeout+time.Second {
+		t.Errorf("statusy zebrane w %v, oczekiwano około %v", elapsed, statusReplyTimeout)
+	}
+	for _, st := range statuses {
+		if st.State != stateBusy {
+			t.Errorf("monitor bez pętli: stan %s, oczekiwano %s", st.State, stateBusy)
+		}
+	}
+}
+
+// Bufor restartów: kolejność od najstarszego i nadpisywanie po zapełnieniu
+func TestRestartRing(t *testing.T) {
+	tests := []struct {
+		added int
+		want  []string
+	}{
+		{0, nil},
+		{2, []string{"r0", "r1"}},
+		{3, []string{"r0", "r1", "r2"}},
+		{4, []string{"r1", "r2", "r3"}},
+		{7, []string{"r4", "r5", "r6"}},
+	}
+	base := time.Now().Add(-time.Hour)
+	for _, tt := range tests {
+		r := newRestartRing(3)
+		for i := 0; i < tt.added; i++ {
+			r.add(restartEvent{Time: base.Add(time.Duration(i) * time.Minute), Reason: fmt.Sprintf("r%d", i)})
+		}
+
+		var got []string
+		for _, ev := range r.all() {
+			got = append(got, ev.Reason)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("po %d zdarzeniach: %v, oczekiwano %v", tt.added, got, tt.want)
+		}
+
+		last, ok := r.last()
+		if ok != (tt.added > 0) || (ok && last.Reason != tt.want[len(tt.want)-1]) {
+			t.Errorf("po %d zdarzeniach: last = %+v, %v", tt.added, last, ok)
+		}
+		// Nadpisane zdarzenia nie są już liczone
+		if n := r.countSince(base.Add(-time.Minute)); n != len(tt.want) {
+			t.Errorf("po %d zdarzeniach: countSince = %d, oczekiwano %d", tt.added, n, len(tt.want))
+		}
+	}
+}
+
+// Czas działania i ostatni restart w statusie
+func TestBuildStatus(t *testing.T) {
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		process     *exec.Cmd
+		startedAt   time.Time
+		restarts    []restartEvent
+		uptime      int64
+		restarts1h  int
+		restarts24h int
+		lastReason  string
+	}{
+		{name: "nigdy nie uruchomiony"},
+		{
+			name:      "proces zakończony",
+			startedAt: now.Add(-time.Minute),
+			restarts:  []restartEvent{{Time: now.Add(-time.Minute), Reason: "crash"}},
+			uptime:    0, restarts1h: 1, restarts24h: 1, lastReason: "crash",
+		},
+		{
+			name:      "proces działa",
+			process:   &exec.Cmd{Process: self},
+			startedAt: now.Add(-90 * time.Second),
+			restarts: []restartEvent{
+				{Time: now.Add(-25 * time.Hour), Reason: "timeout"},
+				{Time: now.Add(-2 * time.Hour), Reason: "schedule"},
+				{Time: now.Add(-30 * time.Minute), Reason: "manual"},
+			},
+			uptime: 90, restarts1h: 1, restarts24h: 2, lastReason: "manual",
+		},
+	}
+	for _, tt := range tests {
+		m := NewMonitor("sleep 30", "/tmp/app.log", 30, 1)
+		m.process = tt.process
+		m.startedAt = tt.startedAt
+		for _, ev := range tt.restarts {
+			m.restarts.add(ev)
+		}
+
+		st := m.buildStatus(stateRunning)
+		if d := st.UptimeSeconds - tt.uptime; d < 0 || d > 1 {
+			t.Errorf("%s: uptime %d, oczekiwano %d", tt.name, st.UptimeSeconds, tt.uptime)
+		}
+		if st.Restarts1h != tt.restarts1h || st.Restarts24h != tt.restarts24h {
+			t.Errorf("%s: restarty 1h/24h %d/%d, oczekiwano %d/%d", tt.name, st.Restarts1h, st.Restarts24h, tt.restarts1h, tt.restarts24h)
+		}
+		if tt.lastReason == "" {
+			if st.LastRestart != nil || st.LastRestartReason != "" {
+				t.Errorf("%s: nieoczekiwany ostatni restart %v %q", tt.name, st.LastRestart, st.LastRestartReason)
+			}
+			continue
+		}
+		last := tt.restarts[len(tt.restarts)-1]
+		if st.LastRestart == nil || !st.LastRestart.Equal(last.Time) || st.LastRestartReason != tt.lastReason {
+			t.Errorf("%s: ostatni restart %v %q, oczekiwano %v %q", tt.name, st.LastRestart, st.LastRestartReason, last.Time, tt.lastReason)
+		}
+	}
+}
+
+// Pola cron: zakresy, kroki i listy
+func TestParseCronField(t *testing.T) {
+	bits := func(values ...int) uint64 {
+		var b uint64
+		for _, v := range values {
+			b |= 1 << uint(v)
+		}
+		return b
+	}
+
+	for _, tc := range []struct {
+		field    string
+		min, max int
+		want     uint64
+	}{
+		{"*", 0, 5, bits(0, 1, 2, 3, 4, 5)},
+		{"5", 0, 59, bits(5)},
+		{"1-5", 0, 7, bits(1, 2, 3, 4, 5)},
+		{"*/15", 0, 59, bits(0, 15, 30, 45)},
+		{"0-30/10", 0, 59, bits(0, 10, 20, 30)},
+		{"5/20", 0, 59, bits(5, 25, 45)},
+		{"1,15,30", 1, 31, bits(1, 15, 30)},
+		{"1-3,10-20/5", 1, 31, bits(1, 2, 3, 10, 15, 20)},
+	} {
+		got, err := parseCronField(tc.field, tc.min, tc.max)
+		if err != nil || got != tc.want {
+			t.Errorf("parseCronField(%q) = %b, %v, oczekiwano %b", tc.field, got, err, tc.want)
+		}
+	}
+
+	for _, field := range []string{"60", "5-1", "*/0", "a", "1-", "", "1,,2", "-1"} {
+		if _, err := parseCronField(field, 0, 59); err == nil {
+			t.Errorf("parseCronField(%q): oczekiwano błędu", field)
+		}
+	}
+}
+
+// Następny termin: przejścia przez miesiąc i rok oraz reguła OR dla dnia miesiąca i tygodnia
+func TestCronNext(t *testing.T) {
+	at := func(value string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+
+	for _, tc := range []struct {
+		expr, after, want string
+	}{
+		{"30 3 * * *", "2024-01-01 03:30", "2024-01-02 03:30"}, // Ściśle po podanym czasie
+		{"15,45 * * * *", "2024-01-01 10:15", "2024-01-01 10:45"},
+		{"0 9-17/4 * * *", "2024-09-02 09:00", "2024-09-02 13:00"},
+		{"@hourly", "2024-09-02 10:59", "2024-09-02 11:00"},
+		{"0 0 1 1 *", "2024-12-31 23:59", "2025-01-01 00:00"},   // Nowy rok
+		{"0 12 31 * *", "2024-04-15 00:00", "2024-05-31 12:00"}, // Kwiecień nie ma 31 dnia
+		{"0 0 29 2 *", "2025-03-01 00:00", "2028-02-29 00:00"},  // Najbliższy rok przestępny
+		{"0 0 * * 1-5", "2024-09-06 10:00", "2024-09-09 00:00"}, // Z piątku na poniedziałek
+		{"0 0 * * 7", "2024-09-07 12:00", "2024-09-08 00:00"},   // 7 to niedziela
+		{"0 0 13 * 5", "2024-09-01 00:00", "2024-09-06 00:00"},  // Piątek albo 13. dzień
+		{"0 0 13 * 5", "2024-09-06 00:00", "2024-09-13 00:00"},
+		{"0 0 1 * */2", "2024-09-01 00:00", "2024-10-01 00:00"},  // */2 nie zawęża dni, jak w vixie cron
+		{"0 0 */10 * 1", "2024-09-01 00:00", "2024-09-02 00:00"}, // Poniedziałek; */10 nie zawęża
+	} {
+		s, err := parseCron(tc.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", tc.expr, err)
+		}
+		if got := s.Next(at(tc.after)); !got.Equal(at(tc.want)) {
+			t.Errorf("%q po %s: %s, oczekiwano %s", tc.expr, tc.after, got.Format("2006-01-02 15:04"), tc.want)
+		}
+	}
+
+	for _, expr := range []string{"0 0 31 2 *", "* * * *", "61 * * * *", "0 0 * 13 *", "@sometimes"} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q): oczekiwano błędu", expr)
+		}
+	}
+}
+
+// Okno serwisowe przechodzące przez północ
+func TestMaintenanceWindow(t *testing.T) {
+	schedule, err := parseCron("0 23 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := maintenanceWindow{schedule: schedule, duration: 2 * time.Hour}
+	day := time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		at     time.Duration // Od północy 2 września
+		active bool
+		end    time.Duration
+	}{
+		{22*time.Hour + 59*time.Minute, false, 0},
+		{23 * time.Hour, true, 25 * time.Hour},
+		{23*time.Hour + 30*time.Minute, true, 25 * time.Hour},
+		{24*time.Hour + 59*time.Minute, true, 25 * time.Hour}, // Po północy, okno z poprzedniego dnia
+		{25 * time.Hour, false, 0},
+		{30 * time.Minute, true, time.Hour}, // Okno z 1 września
+	} {
+		end, active := w.activeAt(day.Add(tc.at))
+		if active != tc.active || (active && !end.Equal(day.Add(tc.end))) {
+			t.Errorf("%v po północy: aktywne %v do %s, oczekiwano %v do %s",
+				tc.at, active, end.Format("01-02 15:04"), tc.active, day.Add(tc.end).Format("01-02 15:04"))
+		}
+	}
+}
+
+// Deduplikacja w oknie dedup_window i limit max_per_hour
+func TestNotifierAllow(t *testing.T) {
+	n, err := newNotifier(config.NotificationConfig{OnRestart: "true", DedupWindow: 60, MaxPerHour: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 9, 2, 12, 0, 0, 0, time.UTC)
+	event := func(reason string, after time.Duration) *NotificationEvent {
+		return &NotificationEvent{Event: eventRestart, Process: "app", Reason: reason, Time: start.Add(after)}
+	}
+
+	if !n.allow(event("cisza", 0)) {
+		t.Fatal("pierwsze zdarzenie pominięte")
+	}
+	if n.allow(event("cisza", 30*time.Second)) || n.allow(event("cisza", 59*time.Second)) {
+		t.Error("powtórzone zdarzenie w oknie dedup_window wysłane")
+	}
+	ev := event("cisza", 61*time.Second)
+	if !n.allow(ev) || ev.Suppressed != 2 {
+		t.Errorf("po oknie dedup_window: suppressed %d, oczekiwano 2", ev.Suppressed)
+	}
+
+	// Inny powód to inne zdarzenie, ale limit godzinowy jest wspólny
+	if !n.allow(event("proces przestał działać", 62*time.Second)) {
+		t.Error("inne zdarzenie pominięte przed limitem")
+	}
+	if n.allow(event("zaplanowany restart", 63*time.Second)) {
+		t.Error("czwarte powiadomienie w godzinie wysłane mimo max_per_hour: 3")
+	}
+	if !n.allow(event("zaplanowany restart", time.Hour+time.Second)) {
+		t.Error("po godzinie limit nie zwolnił miejsca")
+	}
+
+	// Domyślne okno deduplikacji: 60 s
+	n, _ = newNotifier(config.NotificationConfig{OnFailure: "true"})
+	if !n.allow(event("x", 0)) || n.allow(event("x", 59*time.Second)) || !n.allow(event("x", 60*time.Second)) {
+		t.Error("domyślne dedup_window inne niż 60 s")
+	}
+}
+
+// Webhooki są sumowane, pozostałe pola procesu nadpisują globalne
+func TestMergeNotificationConfig(t *testing.T) {
+	global := config.NotificationConfig{
+		Webhooks:    []config.WebhookConfig{{URL: "https://global"}},
+		OnRestart:   "global-restart",
+		OnFailure:   "global-failure",
+		DedupWindow: 300,
+		MaxPerHour:  10,
+	}
+
+	if got := mergeNotificationConfig(global, nil); !reflect.DeepEqual(got, global) {
+		t.Errorf("bez konfiguracji procesu: %+v", got)
+	}
+
+	proc := &config.NotificationConfig{
+		Webhooks:   []config.WebhookConfig{{URL: "https://proc"}},
+		OnFailure:  "proc-failure",
+		MaxPerHour: 2,
+	}
+	got := mergeNotificationConfig(global, proc)
+	want := config.NotificationConfig{
+		Webhooks:    []config.WebhookConfig{{URL: "https://global"}, {URL: "https://proc"}},
+		OnRestart:   "global-restart",
+		OnFailure:   "proc-failure",
+		DedupWindow: 300,
+		MaxPerHour:  2,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scalone:\n%+v\noczekiwano\n%+v", got, want)
+	}
+	if len(global.Webhooks) != 1 {
+		t.Error("scalanie zmieniło listę webhooków konfiguracji globalnej")
+	}
+}
+
+// Webhook dostaje body z szablonu albo JSON zdarzenia, tylko dla subskrybowanych zdarzeń
+func TestWebhookDelivery(t *testing.T) {
+	type request struct {
+		path, contentType, token, body string
+	}
+	received := make(chan request, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- request{r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("X-Token"), string(body)}
+	}))
+	defer server.Close()
+
+	n, err := newNotifier(config.NotificationConfig{Webhooks: []config.WebhookConfig{
+		{URL: server.URL + "/all"},
+		{URL: server.URL + "/failure", Events: []string{eventFailure}, Headers: map[string]string{"X-Token": "abc"},
+			Body: `{"text": {{json (printf "%s: %s" .Process .Reason)}}}`},
+	}})
+	if err != nil {
+		t.Fatalf("newNotifier: %v", err)
+	}
+
+	n.Notify(NotificationEvent{Event: eventRestart, Process: "app", Reason: "cisza", Attempt: 1})
+	n.Wait()
+	if len(received) != 1 {
+		t.Fatalf("restart: %d żądań, oczekiwano 1 (webhook /failure nie subskrybuje restartów)", len(received))
+	}
+	req := <-received
+	var ev NotificationEvent
+	if err := json.Unmarshal([]byte(req.body), &ev); err != nil || req.path != "/all" || ev.Process != "app" || ev.Attempt != 1 || ev.Host == "" {
+		t.Errorf("domyślne body: %v %+v", err, req)
+	}
+
+	n.Notify(NotificationEvent{Event: eventFailure, Process: "app", Reason: `"cytat"`})
+	n.Wait()
+	if len(received) != 2 {
+		t.Fatalf("failure: %d żądań, oczekiwano 2", len(received))
+	}
+	for i := 0; i < 2; i++ {
+		req := <-received
+		if req.path != "/failure" {
+			continue
+		}
+		if req.body != `{"text": "app: \"cytat\""}` || req.token != "abc" || req.contentType != "application/json" {
+			t.Errorf("body z szablonu: %+v", req)
+		}
+	}
+
+	for name, wc := range map[string]config.WebhookConfig{
+		"brak url":           {},
+		"nieznane zdarzenie": {URL: server.URL, Events: []string{"start"}},
+		"błąd szablonu":      {URL: server.URL, Body: "{{.Process"},
+	} {
+		if _, err := newNotifier(config.NotificationConfig{Webhooks: []config.WebhookConfig{wc}}); err == nil {
+			t.Errorf("%s: oczekiwano błędu", name)
+		}
+	}
+}
+
+// Monitor z konfiguracji odrzuca nieprawidłowy harmonogram zamiast kończyć program
+func TestNewRejectsInvalidSchedule(t *testing.T) {
+	cfg := &config.Config{
+		Processes: []config.ProcessConfig{{
+			Name:            "app",
+			Command:         "true",
+			LogFile:         filepath.Join(t.TempDir(), "app.log"),
+			Timeout:         30,
+			Interval:        1,
+			RestartSchedule: "61 * * * *",
+		}},
+	}
+
+	if _, err := New(filepath.Join(t.TempDir(), "monitor_config.yaml"), cfg); err == nil {
+		t.Fatal("oczekiwano błędu dla restart_schedule poza zakresem")
+	}
+}
+
+// Katalog roboczy, zmienne środowiska i przekierowania z konfiguracji trafiają do procesu
+func TestLaunchSpec(t *testing.T) {
+	dir := t.TempDir()
+	workDir := filepath.Join(dir, "app")
+	if err := os.Mkdir(workDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	logFile := filepath.Join(dir, "app.log")
+
+	cfg := &config.Config{
+		Processes: []config.ProcessConfig{{
+			Name:       "app",
+			Command:    `pwd; echo "FOO=$FOO"; echo err >&2; sleep 30`,
+			LogFile:    logFile,
+			Timeout:    30,
+			Interval:   1,
+			WorkingDir: workDir,
+			Env:        []string{"FOO=bar baz"},
+			Stdout:     logFile,
+			Stderr:     logFile,
+		}},
+	}
+
+	sup, err := New(filepath.Join(dir, "monitor_config.yaml"), cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- sup.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	want := workDir + "\nFOO=bar baz\nerr\n"
+	waitFor(t, "wyjście procesu w pliku logów", func() bool {
+		data, _ := os.ReadFile(logFile)
+		return string(data) == want
+	})
+}
+
+// Błędne ustawienia uruchomienia są zgłaszane przy tworzeniu monitora
+func TestLaunchSpecErrors(t *testing.T) {
+	dir := t.TempDir()
+	base := config.ProcessConfig{Name: "app", Command: "true", LogFile: filepath.Join(dir, "app.log"), Timeout: 30, Interval: 1}
+
+	tests := map[string]func(pc *config.ProcessConfig){
+		"brak katalogu":  func(pc *config.ProcessConfig) { pc.WorkingDir = filepath.Join(dir, "missing") },
+		"env bez =":      func(pc *config.ProcessConfig) { pc.Env = []string{"FOO"} },
+		"nieznany user":  func(pc *config.ProcessConfig) { pc.User = "no-such-user-monitor-mutex" },
+		"nieznana grupa": func(pc *config.ProcessConfig) { pc.Group = "no-such-group-monitor-mutex" },
+	}
+	for name, modify := range tests {
+		pc := base
+		modify(&pc)
+		if _, err := NewMonitorFromConfig(pc, config.NotificationConfig{}); err == nil {
+			t.Errorf("%s: oczekiwano błędu", name)
+		}
+	}
+}
+
+// Timeout wyuczony z przerw w logach: stały do zebrania próbek, potem percentyl × margines w granicach
+func TestAdaptiveTimeout(t *testing.T) {
+	dir := t.TempDir()
+	pc := config.ProcessConfig{
+		Name: "app", Command: "true", LogFile: filepath.Join(dir, "app.log"), Timeout: 60, Interval: 5,
+		AdaptiveTimeout: &config.AdaptiveTimeoutConfig{Max: 300, MinSamples: 5, History: 10},
+	}
+	m, err := NewMonitorFromConfig(pc, config.NotificationConfig{})
+	if err != nil {
+		t.Fatalf("NewMonitorFromConfig: %v", err)
+	}
+
+	now := time.Now()
+	write := func(gap time.Duration) {
+		now = now.Add(gap)
+		m.observeLogWrite(now)
+	}
+	write(0) // Pierwszy zapis tylko ustawia punkt odniesienia
+	for i := 0; i < 4; i++ {
+		write(10 * time.Second)
+	}
+	if got := m.effectiveTimeout(); got != 60*time.Second {
+		t.Errorf("przed zebraniem próbek timeout = %v, oczekiwano stałego 60s", got)
+	}
+
+	write(12 * time.Second)
+	if got := m.effectiveTimeout(); got != 24*time.Second {
+		t.Errorf("wyuczony timeout = %v, oczekiwano 24s (2 × p99 12s)", got)
+	}
+
+	// Przerwa po restarcie procesu nie trafia do historii
+	m.resetLogGap()
+	write(time.Hour)
+	write(20 * time.Second)
+	if got := m.effectiveTimeout(); got != 40*time.Second {
+		t.Errorf("po resecie timeout = %v, oczekiwano 40s", got)
+	}
+
+	// Górna granica i bufor cykliczny (10 ostatnich przerw)
+	for i := 0; i < 10; i++ {
+		write(10 * time.Minute)
+	}
+	if got := m.effectiveTimeout(); got != 300*time.Second {
+		t.Errorf("timeout = %v, oczekiwano granicy max 300s", got)
+	}
+	if n := len(m.adaptive.samples()); n != 10 {
+		t.Errorf("historia ma %d przerw, oczekiwano 10", n)
+	}
+
+	// Historia przetrwa restart monitora przez plik stanu
+	store, err := openStateStore(filepath.Join(dir, "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.state = store
+	m.saveState()
+	restored, _ := NewMonitorFromConfig(pc, config.NotificationConfig{})
+	reopened, err := openStateStore(filepath.Join(dir, "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored.restoreState(reopened)
+	if st := restored.buildStatus(stateStopped); st.TimeoutSeconds != 300 || st.Adaptive == nil || st.Adaptive.Samples != 10 || st.Adaptive.Learning {
+		t.Errorf("status po przywróceniu: %+v %+v", st, st.Adaptive)
+	}
+
+	// Błędne granice
+	pc.AdaptiveTimeout = &config.AdaptiveTimeoutConfig{Min: 100, Max: 50}
+	if _, err := NewMonitorFromConfig(pc, config.NotificationConfig{}); err == nil {
+		t.Error("oczekiwano błędu dla max < min")
+	}
+}
+
+// Komunikaty sd_notify i plik heartbeat: gotowość, sygnały życia i żądanie restartu
+func TestWatchdog(t *testing.T) {
+	dir := t.TempDir()
+	heartbeat := filepath.Join(dir, "heartbeat")
+
+	for name, cfg := range map[string]config.WatchdogConfig{
+		"bez notify i pliku":               {Timeout: 30},
+		"ready_timeout bez notify":         {HeartbeatFile: heartbeat, Timeout: 30, ReadyTimeout: 10},
+		"heartbeat_file bez timeout":       {HeartbeatFile: heartbeat},
+		"timeout krótszy niż 2 × interval": {Notify: true, Timeout: 5},
+	} {
+		if _, err := newWatchdog(cfg, 5*time.Second); err == nil {
+			t.Errorf("%s: oczekiwano błędu", name)
+		}
+	}
+
+	w, err := newWatchdog(config.WatchdogConfig{Notify: true, HeartbeatFile: heartbeat, Timeout: 30, ReadyTimeout: 10}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("newWatchdog: %v", err)
+	}
+	start := time.Now()
+
+	if !w.starting() || w.check(start, start.Add(5*time.Second)) != "" {
+		t.Error("przed READY=1 proces powinien startować bez restartu")
+	}
+	if w.check(start, start.Add(11*time.Second)) == "" {
+		t.Error("oczekiwano restartu po przekroczeniu ready_timeout")
+	}
+
+	w.handle("READY=1\nSTATUS=Przyjmuję połączenia\nMAINPID=123", start.Add(2*time.Second))
+	if w.starting() || w.snapshot(start.Add(4*time.Second)).Status != "Przyjmuję połączenia" {
+		t.Errorf("po READY=1: %+v", w.snapshot(start.Add(4*time.Second)))
+	}
+	w.handle("WATCHDOG=1", start.Add(20*time.Second))
+	if r := w.check(start, start.Add(45*time.Second)); r != "" {
+		t.Errorf("25 s po WATCHDOG=1 niepotrzebny restart: %s", r)
+	}
+	if w.check(start, start.Add(51*time.Second)) == "" {
+		t.Error("oczekiwano restartu po 31 s ciszy")
+	}
+
+	// Dotknięcie pliku heartbeat jest sygnałem życia
+	touched := start.Add(40 * time.Second)
+	if err := os.WriteFile(heartbeat, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(heartbeat, touched, touched); err != nil {
+		t.Fatal(err)
+	}
+	if r := w.check(start, start.Add(60*time.Second)); r != "" {
+		t.Errorf("20 s po dotknięciu pliku niepotrzebny restart: %s", r)
+	}
+
+	w.handle("WATCHDOG=trigger", start.Add(61*time.Second))
+	if w.check(start, start.Add(61*time.Second)) == "" {
+		t.Error("oczekiwano restartu po WATCHDOG=trigger")
+	}
+
+	w.reset()
+	if st := w.snapshot(start); st.Ready || st.Status != "" || st.LastPingSeconds != -1 {
+		t.Errorf("po resecie: %+v", st)
+	}
+}
+
+// Proces dostaje NOTIFY_SOCKET i jest w stanie starting, dopóki nie wyśle READY=1
+func TestWatchdogNotify(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "env")
+	configFile := filepath.Join(dir, "monitor_config.yaml")
+
+	cfg := &config.Config{
+		Processes: []config.ProcessConfig{{
+			Name:     "app",
+			Command:  `echo "$NOTIFY_SOCKET $WATCHDOG_USEC" > ` + envFile + `; sleep 30`,
+			Timeout:  30,
+			Interval: 1,
+			Watchdog: &config.WatchdogConfig{Notify: true, Timeout: 30, ReadyTimeout: 60},
+		}},
+	}
+	sup, err := New(configFile, cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	monitor := sup.Monitors()[0]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- sup.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	var fields []string
+	waitFor(t, "proces zapisał NOTIFY_SOCKET", func() bool {
+		data, _ := os.ReadFile(envFile)
+		fields = strings.Fields(string(data))
+		return len(fields) == 2
+	})
+	if want := notifySocketPath(dir, "app"); fields[0] != want || fields[1] != "30000000" {
+		t.Errorf("środowisko procesu: %v, oczekiwano %s 30000000", fields, want)
+	}
+	waitFor(t, "stan starting", func() bool { return monitor.Status().State == stateStarting })
+
+	conn, err := net.Dial("unixgram", fields[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("READY=1\nSTATUS=gotowy")); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "stan running po READY=1", func() bool {
+		st := monitor.Status()
+		return st.State == stateRunning && st.Watchdog != nil && st.Watchdog.Ready && st.Watchdog.Status == "gotowy"
+	})
+}
+
+// Wpis konfiguracji przekłada się na usługę systemd z cytowaniem i listą nieprzeniesionych opcji
+func TestSystemdUnit(t *testing.T) {
+	unit := SystemdUnitFor(config.ProcessConfig{
+		Name:            "Web Server",
+		Command:         `echo "$PORT 100%" >> /tmp/web.log`,
+		LogFile:         "/tmp/web.log",
+		Timeout:         60,
+		Interval:        5,
+		Env:             []string{`GREETING=say "hi"`},
+		User:            "web",
+		RestartSchedule: "0 3 * * *",
+		Watchdog:        &config.WatchdogConfig{Notify: true, Timeout: 30, ReadyTimeout: 120},
+	})
+
+	if unit.Name != "Web_Server.service" {
+		t.Errorf("nazwa jednostki %q", unit.Name)
+	}
+	for _, line := range []string{
+		`ExecStart=/bin/sh -c "echo \"$$PORT 100%%\" >> /tmp/web.log"`,
+		`Environment="GREETING=say \"hi\""`,
+		"User=web",
+		"Type=notify",
+		"WatchdogSec=30",
+		"TimeoutStartSec=120",
+		"Restart=always",
+		"RestartSec=5",
+		"TimeoutStopSec=5",
+		"StartLimitBurst=3",
+	} {
+		if !strings.Contains(unit.Content, "\n"+line+"\n") {
+			t.Errorf("brak linii %s w:\n%s", line, unit.Content)
+		}
+	}
+	if len(unit.Unmapped) != 2 {
+		t.Errorf("oczekiwano 2 nieprzeniesionych opcji (log_file, restart_schedule), są %q", unit.Unmapped)
+	}
+
+	self := SupervisorUnit("/usr/local/bin/monitor_mutex", "/etc/monitor/config.yaml")
+	if !strings.Contains(self.Content, `ExecStart="/usr/local/bin/monitor_mutex" --config "/etc/monitor/config.yaml"`) ||
+		!strings.Contains(self.Content, "KillMode=mixed") {
+		t.Errorf("jednostka monitora:\n%s", self.Content)
+	}
+}
+
+// Wpis z instances daje kilka monitorów, a scale dodaje i zatrzymuje kopie w działającym monitorze
+func TestScaleInstances(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "monitor_config.yaml")
+
+	cfg := &config.Config{
+		Processes: []config.ProcessConfig{{
+			Name:      "worker",
+			Command:   "while true; do echo port=$PORT >> " + filepath.Join(dir, "worker-{{.Index}}.log") + "; sleep 0.2; done",
+			LogFile:   filepath.Join(dir, "worker-{{.Index}}.log"),
+			Timeout:   30,
+			Interval:  1,
+			Env:       []string{"PORT={{add 8000 .Index}}"},
+			Instances: 2,
+		}},
+	}
+
+	sup, err := New(configFile, cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if monitors := sup.Monitors(); len(monitors) != 2 || monitors[1].Name() != "worker-1" {
+		t.Fatalf("oczekiwano kopii worker-0 i worker-1, są %d monitory", len(monitors))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- sup.Run(ctx) }()
+
+	socket := ControlSocketPath(configFile, cfg)
+	waitFor(t, "obie kopie działają", func() bool {
+		statuses, err := SendCommand(socket, "status", "")
+		return err == nil && len(statuses) == 2 && statuses[0].State == stateRunning && statuses[1].State == stateRunning
+	})
+
+	// Kopię można wskazać nazwą wpisu albo dowolnej kopii
+	statuses, err := SendScale(socket, "worker-0", 3)
+	if err != nil {
+		t.Fatalf("scale 3: %v", err)
+	}
+	if len(statuses) != 3 || statuses[2].Name != "worker-2" {
+		t.Fatalf("po scale 3: %+v", statuses)
+	}
+	waitFor(t, "nowa kopia pisze z własnym PORT", func() bool {
+		data, _ := os.ReadFile(filepath.Join(dir, "worker-2.log"))
+		return strings.Contains(string(data), "port=8002")
+	})
+
+	removed := sup.Monitors()[1]
+	pid := removed.Status().PID
+	if statuses, err = SendScale(socket, "worker", 1); err != nil || len(statuses) != 1 {
+		t.Fatalf("scale 1: %v %+v", err, statuses)
+	}
+	if st := removed.Status(); st.State != stateStopped {
+		t.Errorf("usunięta kopia: stan %s, oczekiwano %s", st.State, stateStopped)
+	}
+	if pid > 0 && syscall.Kill(pid, 0) == nil {
+		t.Errorf("proces usuniętej kopii (PID %d) nadal działa", pid)
+	}
+
+	if _, err := SendScale(socket, "worker", 0); err == nil {
+		t.Error("oczekiwano błędu przy zatrzymaniu ostatniej kopii")
+	}
+	if _, err := SendScale(socket, "nieznany", 2); err == nil {
+		t.Error("oczekiwano błędu dla nieznanego procesu")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run nie zakończył się po anulowaniu kontekstu")
+	}
+}
+
+// Stan zapisany przez put wraca po ponownym otwarciu pliku
+func TestStateStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := openStateStore(path)
+	if err != nil {
+		t.Fatalf("brak pliku stanu: %v", err)
+	}
+	if _, ok := store.get("app"); ok {
+		t.Fatal("pusty stan zawiera proces app")
+	}
+
+	saved := processState{
+		RetryCount:   2,
+		LastFailure:  time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		PID:          1234,
+		PIDStartTime: 5678,
+		Restarts:     []restartEvent{{Time: time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC), Reason: "timeout"}},
+		LogGaps:      []float64{1.5, 3},
+	}
+	if err := store.put("app", saved); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0644 {
+		t.Fatalf("plik stanu: %v %v", info, err)
+	}
+
+	reopened, err := openStateStore(path)
+	if err != nil {
+		t.Fatalf("ponowne otwarcie: %v", err)
+	}
+	got, ok := reopened.get("app")
+	if !ok {
+		t.Fatal("brak procesu app po ponownym otwarciu")
+	}
+	saved.UpdatedAt = got.UpdatedAt
+	if !reflect.DeepEqual(got, saved) {
+		t.Errorf("odczytano %+v, oczekiwano %+v", got, saved)
+	}
+	if got.UpdatedAt.IsZero() {
+		t.Error("put nie ustawił updated_at")
+	}
+
+	for name, content := range map[string]string{
+		"uszkodzony":  "{",
+		"inna wersja": `{"version": 99, "processes": {}}`,
+		"brak wersji": `{"processes": {}}`,
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := openStateStore(path); err == nil {
+			t.Errorf("%s: oczekiwano błędu", name)
+		}
+	}
+}
+
+// Uruchamia sleep pod nazwą zawierającą nawiasy i spacje, jak w polu comm
+func startTrickyProcess(t *testing.T) (*exec.Cmd, string) {
+	t.Helper()
+	sleepPath, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("brak sleep")
+	}
+	data, err := os.ReadFile(sleepPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "a) b (c")
+	if err := os.WriteFile(path, data, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(path, "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	return cmd, path + " 30"
+}
+
+// Czas startu i tożsamość procesu, którego comm zawiera ')' i spacje
+func TestIsSameProcess(t *testing.T) {
+	cmd, _ := startTrickyProcess(t)
+	pid := cmd.Process.Pid
+
+	startTime, err := procStartTime(pid)
+	if err != nil {
+		t.Fatalf("procStartTime: %v", err)
+	}
+	self, err := procStartTime(os.Getpid())
+	if err != nil {
+		t.Fatalf("procStartTime własnego procesu: %v", err)
+	}
+	if startTime < self {
+		t.Errorf("czas startu dziecka %d wcześniejszy niż testu %d", startTime, self)
+	}
+
+	tests := []struct {
+		name      string
+		pid       int
+		startTime uint64
+		want      bool
+	}{
+		{"ten sam proces", pid, startTime, true},
+		{"inny czas startu", pid, startTime + 1, false},
+		{"nieistniejący PID", 1 << 22, startTime, false},
+	}
+	for _, tt := range tests {
+		if got := isSameProcess(tt.pid, tt.startTime); got != tt.want {
+			t.Errorf("%s: isSameProcess = %v, oczekiwano %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// restoreState przejmuje proces zgodny z zapisanym PID i czasem startu
+func TestRestoreStateAdoption(t *testing.T) {
+	cmd, command := startTrickyProcess(t)
+	pid := cmd.Process.Pid
+	startTime, err := procStartTime(pid)
+	if err != nil {
+		t.Fatalf("procStartTime: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		saved     string // Komenda zapisana w stanie
+		startTime uint64
+		adopt     bool
+	}{
+		{"zgodny proces", command, startTime, true},
+		{"stan bez komendy", "", startTime, true},
+		{"PID użyty ponownie", command, startTime + 1, false},
+	}
+	for _, tt := range tests {
+		store, err := openStateStore(filepath.Join(t.TempDir(), "state.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		st := processState{
+			RetryCount:   1,
+			PID:          pid,
+			PIDStartTime: tt.startTime,
+			Command:      tt.saved,
+			Restarts:     []restartEvent{{Time: time.Now(), Reason: "timeout"}},
+		}
+		if err := store.put(command, st); err != nil {
+			t.Fatal(err)
+		}
+
+		m := NewMonitor(command, "/tmp/app.log", 30, 1)
+		m.restoreState(store)
+		if m.retryCount != 1 || len(m.restarts.all()) != 1 {
+			t.Errorf("%s: przywrócono próby %d, historia %d", tt.name, m.retryCount, len(m.restarts.all()))
+		}
+		if adopted := m.pid() == pid; adopted != tt.adopt || m.adopted != tt.adopt {
+			t.Errorf("%s: PID %d (adopted=%v), oczekiwano przejęcia: %v", tt.name, m.pid(), m.adopted, tt.adopt)
+		}
+	}
+	if syscall.Kill(pid, 0) != nil {
+		t.Error("restoreState zatrzymał przejmowany proces")
+	}
+}
+
+// Proces uruchomiony przez sh -c z cudzysłowami: bash wykonuje prostą komendę przez exec,
+// więc cmdline (sleep 33) różni się od komendy z konfiguracji, a proces i tak musi zostać
+// przejęty. Jawne exec daje ten sam efekt niezależnie od tego, czym jest /bin/sh.
+func TestRestoreStateShellCommand(t *testing.T) {
+	store, err := openStateStore(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const command = `exec sleep "33"`
+
+	first := NewMonitor(command, "/tmp/app.log", 30, 1)
+	first.restoreState(store)
+	if err := first.startProcess(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer first.killProcess()
+	pid := first.pid()
+	first.saveState()
+	if cmdline, _ := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid)); strings.Contains(string(cmdline), `"`) {
+		t.Fatalf("cmdline zachowała cudzysłowy: %q", cmdline)
+	}
+
+	second := NewMonitor(command, "/tmp/app.log", 30, 1)
+	second.restoreState(store)
+	if second.pid() != pid || !second.adopted {
+		t.Errorf("proces sh -c (PID %d) nie został przejęty: PID %d", pid, second.pid())
+	}
+}
+
+// Proces uruchomiony z poprzednią komendą jest zatrzymywany, a nie zostawiany obok nowej kopii
+func TestRestoreStateChangedCommand(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	defer cmd.Process.Kill()
+
+	startTime, err := procStartTime(cmd.Process.Pid)
+	if err != nil {
+		t.Fatalf("procStartTime: %v", err)
+	}
+	store, err := openStateStore(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := processState{PID: cmd.Process.Pid, PIDStartTime: startTime, Command: "sleep 30"}
+	if err := store.put("worker", st); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMonitor("sleep 60", "/tmp/app.log", 30, 1)
+	m.name = "worker"
+	m.restoreState(store)
+	if m.pid() != 0 {
+		t.Errorf("przejęto proces ze starą komendą (PID %d)", m.pid())
+	}
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Error("proces ze starą komendą nadal działa")
+	}
+	if saved, _ := store.get("worker"); saved.PID != 0 {
+		t.Errorf("stan nadal wskazuje zatrzymany proces: %+v", saved)
+	}
+}
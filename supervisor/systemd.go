@@ -0,0 +1,148 @@
+package supervisor
+
+import (
+	"fmt"
+	"monitor_mutex/config"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Jednostka systemd wygenerowana z konfiguracji
+type SystemdUnit struct {
+	Name     string   // Nazwa pliku jednostki (api.service)
+	Content  string   // Treść pliku
+	Unmapped []string // Ustawienia monitora bez odpowiednika w systemd
+}
+
+// Przekłada wpis konfiguracji na równoważną usługę systemd
+//
+// Jednostka odtwarza to, jak monitor uruchamia i restartuje proces: sh -c, SIGTERM
+// i SIGKILL po stopTimeout, restart po każdym zakończeniu, limit prób. Kontroli ciszy
+// w logach systemd nie ma - takie ustawienia trafiają do Unmapped i komentarza w pliku.
+func SystemdUnitFor(pc config.ProcessConfig) SystemdUnit {
+	unit := SystemdUnit{Name: safeName(pc.Name) + ".service"}
+
+	if pc.LogFile != "" {
+		unit.Unmapped = append(unit.Unmapped, fmt.Sprintf("log_file/timeout: systemd nie wykrywa %ds ciszy w %s - aplikacja może wysyłać WATCHDOG=1 (WatchdogSec=)", pc.Timeout, pc.LogFile))
+	}
+	if pc.AdaptiveTimeout != nil {
+		unit.Unmapped = append(unit.Unmapped, "adaptive_timeout: brak odpowiednika")
+	}
+	if pc.RestartSchedule != "" {
+		unit.Unmapped = append(unit.Unmapped, fmt.Sprintf("restart_schedule %q: użyj jednostki .timer wywołującej systemctl restart %s", pc.RestartSchedule, unit.Name))
+	}
+	if len(pc.MaintenanceWindows) > 0 {
+		unit.Unmapped = append(unit.Unmapped, "maintenance_windows: brak odpowiednika")
+	}
+	if pc.Notifications != nil {
+		unit.Unmapped = append(unit.Unmapped, "notifications: użyj OnFailure= z jednostką wysyłającą powiadomienie")
+	}
+	watchdog := pc.Watchdog
+	if watchdog == nil {
+		watchdog = &config.WatchdogConfig{}
+	}
+	if watchdog.HeartbeatFile != "" {
+		unit.Unmapped = append(unit.Unmapped, fmt.Sprintf("watchdog.heartbeat_file %s: systemd przyjmuje tylko WATCHDOG=1 przez NOTIFY_SOCKET", watchdog.HeartbeatFile))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Wygenerowane przez monitor_mutex export systemd z wpisu %q\n", pc.Name)
+	if len(unit.Unmapped) > 0 {
+		b.WriteString("# Bez odpowiednika w systemd:\n")
+		for _, note := range unit.Unmapped {
+			fmt.Fprintf(&b, "#   - %s\n", note)
+		}
+	}
+
+	b.WriteString("\n[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s\n", systemdEscape(pc.Name))
+	b.WriteString("After=network.target\n")
+	// Monitor poddaje się po defaultMaxRetries próbach bez stableIterationsReset spokojnych sprawdzeń
+	fmt.Fprintf(&b, "StartLimitIntervalSec=%d\n", stableIterationsReset*pc.Interval)
+	fmt.Fprintf(&b, "StartLimitBurst=%d\n", defaultMaxRetries)
+
+	b.WriteString("\n[Service]\n")
+	if watchdog.Notify {
+		b.WriteString("Type=notify\n")
+		b.WriteString("NotifyAccess=all\n") // Komunikaty może wysyłać proces potomny sh -c
+	} else {
+		b.WriteString("Type=simple\n")
+	}
+	fmt.Fprintf(&b, "ExecStart=/bin/sh -c %s\n", systemdExecQuote(pc.Command))
+	if pc.WorkingDir != "" {
+		fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdEscape(pc.WorkingDir))
+	}
+	if pc.User != "" {
+		fmt.Fprintf(&b, "User=%s\n", systemdEscape(pc.User))
+	}
+	if pc.Group != "" {
+		fmt.Fprintf(&b, "Group=%s\n", systemdEscape(pc.Group))
+	}
+	for _, entry := range pc.Env {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote(entry))
+	}
+	if pc.Stdout != "" {
+		fmt.Fprintf(&b, "StandardOutput=append:%s\n", systemdEscape(pc.Stdout))
+	}
+	if pc.Stderr != "" {
+		fmt.Fprintf(&b, "StandardError=append:%s\n", systemdEscape(pc.Stderr))
+	}
+	if watchdog.Notify && watchdog.Timeout > 0 {
+		fmt.Fprintf(&b, "WatchdogSec=%d\n", watchdog.Timeout)
+	}
+	if watchdog.ReadyTimeout > 0 {
+		fmt.Fprintf(&b, "TimeoutStartSec=%d\n", watchdog.ReadyTimeout)
+	}
+	// Monitor restartuje proces po każdym zakończeniu, najpóźniej po jednym sprawdzeniu
+	b.WriteString("Restart=always\n")
+	fmt.Fprintf(&b, "RestartSec=%d\n", pc.Interval)
+	b.WriteString("KillSignal=SIGTERM\n")
+	fmt.Fprintf(&b, "TimeoutStopSec=%d\n", int(stopTimeout/time.Second))
+
+	b.WriteString("\n[Install]\n")
+	b.WriteString("WantedBy=multi-user.target\n")
+
+	unit.Content = b.String()
+	return unit
+}
+
+// Jednostka dla samego monitora pilnującego wszystkich procesów z configFile
+func SupervisorUnit(executable, configFile string) SystemdUnit {
+	var b strings.Builder
+	b.WriteString("# Wygenerowane przez monitor_mutex export systemd --self\n")
+	b.WriteString("\n[Unit]\n")
+	fmt.Fprintf(&b, "Description=monitor_mutex (%s)\n", systemdEscape(configFile))
+	b.WriteString("After=network.target\n")
+
+	b.WriteString("\n[Service]\n")
+	b.WriteString("Type=simple\n")
+	fmt.Fprintf(&b, "ExecStart=%s --config %s\n", systemdExecQuote(executable), systemdExecQuote(configFile))
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdEscape(filepath.Dir(configFile)))
+	b.WriteString("Restart=always\n")
+	b.WriteString("RestartSec=10\n")
+	// SIGTERM tylko do monitora - sam zatrzymuje procesy i zapisuje stan; SIGKILL dla wszystkich po czasie
+	b.WriteString("KillMode=mixed\n")
+	fmt.Fprintf(&b, "TimeoutStopSec=%d\n", int(3*stopTimeout/time.Second))
+
+	b.WriteString("\n[Install]\n")
+	b.WriteString("WantedBy=multi-user.target\n")
+
+	return SystemdUnit{Name: "monitor_mutex.service", Content: b.String()}
+}
+
+// Chroni przed rozwijaniem specyfikatorów systemd (%n, %h...)
+func systemdEscape(value string) string {
+	return strings.ReplaceAll(value, "%", "%%")
+}
+
+// Cytuje wartość dla Environment= (bez rozwijania specyfikatorów)
+func systemdQuote(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+	return `"` + systemdEscape(value) + `"`
+}
+
+// Cytuje argument ExecStart= - tu systemd rozwija też $VAR, a zmienne ma rozwinąć powłoka
+func systemdExecQuote(value string) string {
+	return systemdQuote(strings.ReplaceAll(value, "$", "$$"))
+}
@@ -0,0 +1,606 @@
+package supervisor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"monitor_mutex/procfs"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Parametry dashboardu
+const (
+	tuiRefresh       = time.Second // Jak często odświeżać ekran
+	tuiSparkWidth    = 30          // Liczba próbek aktywności logów na wykresie
+	tuiMaxEvents     = 200         // Ile komunikatów monitora trzymać w pamięci
+	tuiTailReadBytes = 16 * 1024   // Ile bajtów z końca logu czytać dla podglądu
+)
+
+// Kody ANSI
+const (
+	ansiAltScreenOn  = "\x1b[?1049h"
+	ansiAltScreenOff = "\x1b[?1049l"
+	ansiHideCursor   = "\x1b[?25l"
+	ansiShowCursor   = "\x1b[?25h"
+	ansiHome         = "\x1b[H"
+	ansiClearLine    = "\x1b[K"
+	ansiClearDown    = "\x1b[J"
+	ansiReset        = "\x1b[0m"
+	ansiBold         = "\x1b[1m"
+	ansiReverse      = "\x1b[7m"
+	ansiDim          = "\x1b[2m"
+	ansiRed          = "\x1b[31m"
+	ansiGreen        = "\x1b[32m"
+	ansiYellow       = "\x1b[33m"
+	ansiCyan         = "\x1b[36m"
+)
+
+// Znaki wykresu aktywności logów
+var sparkChars = []rune("▁▂▃▄▅▆▇█")
+
+// Próbki zbierane przez dashboard dla jednego monitora
+type tuiSamples struct {
+	logSize  int64     // Rozmiar logu przy ostatniej próbce
+	activity []int64   // Przyrosty logu (bajty na odświeżenie)
+	cpuTicks uint64    // Suma utime+stime drzewa procesu przy ostatniej próbce
+	cpuAt    time.Time // Czas ostatniej próbki CPU
+	cpuPct   float64
+	rssKB    int64
+}
+
+// Pełnoekranowy podgląd wszystkich monitorów
+type Dashboard struct {
+	source   func() []*Monitor // Bieżąca lista monitorów (scale dodaje i usuwa kopie)
+	monitors []*Monitor
+	term     *os.File // Prawdziwy terminal (os.Stdout jest przechwycony)
+	samples  map[*Monitor]*tuiSamples
+	statuses map[*Monitor]ProcessStatus // Statusy z ostatniej próbki
+	selected int
+	message  string   // Wynik ostatniej akcji
+	cleanup  []func() // Przywrócenie wyjścia i trybu terminala
+
+	eventsMu sync.Mutex
+	events   []string // Ostatnie komunikaty monitorów
+}
+
+// Rozmiar okna terminala
+type winsize struct {
+	rows, cols, xpixel, ypixel uint16
+}
+
+// Zwraca rozmiar terminala (domyślnie 24x80)
+func terminalSize(f *os.File) (int, int) {
+	var ws winsize
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 || ws.rows == 0 || ws.cols == 0 {
+		return 24, 80
+	}
+	return int(ws.rows), int(ws.cols)
+}
+
+// Przełącza terminal w tryb znakowy bez echa; zwraca funkcję przywracającą
+func enableCbreak(f *os.File) (func(), error) {
+	var orig syscall.Termios
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TCGETS), uintptr(unsafe.Pointer(&orig))); errno != 0 {
+		return nil, fmt.Errorf("to nie jest terminal: %v", errno)
+	}
+
+	// Ctrl+C nadal generuje SIGINT (ISIG zostaje), więc monitory zamkną się normalnie
+	raw := orig
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TCSETS), uintptr(unsafe.Pointer(&raw))); errno != 0 {
+		return nil, fmt.Errorf("nie można ustawić trybu terminala: %v", errno)
+	}
+
+	return func() {
+		syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TCSETS), uintptr(unsafe.Pointer(&orig)))
+	}, nil
+}
+
+// Przekierowuje komunikaty monitorów (fmt.Printf i log) do panelu zdarzeń
+func (d *Dashboard) captureOutput() (func(), error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	origStdout := os.Stdout
+	os.Stdout = w
+	log.SetOutput(w)
+
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			d.addEvent(scanner.Text())
+		}
+	}()
+
+	return func() {
+		os.Stdout = origStdout
+		log.SetOutput(os.Stderr)
+		w.Close()
+	}, nil
+}
+
+// Dodaje komunikat do panelu zdarzeń
+func (d *Dashboard) addEvent(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+
+	d.eventsMu.Lock()
+	defer d.eventsMu.Unlock()
+	d.events = append(d.events, time.Now().Format("15:04:05")+" "+line)
+	if len(d.events) > tuiMaxEvents {
+		d.events = d.events[len(d.events)-tuiMaxEvents:]
+	}
+}
+
+// Zwraca n ostatnich komunikatów
+func (d *Dashboard) lastEvents(n int) []string {
+	d.eventsMu.Lock()
+	defer d.eventsMu.Unlock()
+	if n > len(d.events) {
+		n = len(d.events)
+	}
+	return append([]string(nil), d.events[len(d.events)-n:]...)
+}
+
+// Przygotowuje terminal i przechwytuje komunikaty - wywołać przed startem monitorów.
+// monitors jest odpytywana przy każdym odświeżeniu (np. Supervisor.Monitors).
+func StartDashboard(monitors func() []*Monitor) (*Dashboard, error) {
+	d := &Dashboard{
+		source:   monitors,
+		monitors: monitors(),
+		term:     os.Stdout,
+		samples:  make(map[*Monitor]*tuiSamples),
+	}
+
+	restoreTerm, err := enableCbreak(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	restoreOutput, err := d.captureOutput()
+	if err != nil {
+		restoreTerm()
+		return nil, err
+	}
+
+	fmt.Fprint(d.term, ansiAltScreenOn+ansiHideCursor)
+	d.cleanup = []func(){restoreOutput, restoreTerm}
+	return d, nil
+}
+
+// Przywraca terminal; ostatnie komunikaty trafiają na zwykłe wyjście
+func (d *Dashboard) Close() {
+	fmt.Fprint(d.term, ansiShowCursor+ansiAltScreenOff)
+	for _, f := range d.cleanup {
+		f()
+	}
+	for _, line := range d.lastEvents(10) {
+		fmt.Println(line)
+	}
+}
+
+// Pętla dashboardu; kończy się, gdy zamknięty zostanie kanał done.
+// quit jest wywoływane po naciśnięciu q - powinno zatrzymać monitory tak jak SIGINT.
+func (d *Dashboard) Run(done <-chan struct{}, quit func()) {
+	keys := make(chan byte, 16)
+	go readKeys(os.Stdin, keys)
+
+	ticker := time.NewTicker(tuiRefresh)
+	defer ticker.Stop()
+
+	d.sample()
+	d.render()
+	for {
+		select {
+		case <-done:
+			return
+		case key := <-keys:
+			if key == 'q' {
+				// Zamknięcie dashboardu zatrzymuje monitory tak jak Ctrl+C
+				quit()
+				d.message = "Zamykanie monitorów..."
+			} else {
+				d.handleKey(key)
+			}
+			d.render()
+		case <-ticker.C:
+			d.sample()
+			d.render()
+		}
+	}
+}
+
+// Czyta klawisze; strzałki (ESC [ A/B) zamienia na k/j
+func readKeys(r io.Reader, keys chan<- byte) {
+	reader := bufio.NewReader(r)
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return
+		}
+		if b == 0x1b {
+			if next, _ := reader.ReadByte(); next == '[' {
+				switch arrow, _ := reader.ReadByte(); arrow {
+				case 'A':
+					b = 'k'
+				case 'B':
+					b = 'j'
+				default:
+					continue
+				}
+			}
+		}
+		keys <- b
+	}
+}
+
+// Obsługuje klawisz: nawigacja i akcje na wybranym procesie
+func (d *Dashboard) handleKey(key byte) {
+	var action string
+	switch key {
+	case 'k':
+		if d.selected > 0 {
+			d.selected--
+		}
+		return
+	case 'j':
+		if d.selected < len(d.monitors)-1 {
+			d.selected++
+		}
+		return
+	case 'r':
+		action = ActionRestart
+	case 's':
+		action = ActionStop
+	case 'u':
+		action = ActionStart
+	default:
+		return
+	}
+
+	m := d.monitors[d.selected]
+	d.message = fmt.Sprintf("%s: %s...", m.displayName(), action)
+	d.render()
+
+	// Akcja może trwać kilka sekund (zatrzymywanie procesu) - wykonaj w tle
+	go func() {
+		if err := m.Control(action); err != nil {
+			d.addEvent(fmt.Sprintf("❌ %s %s: %v", action, m.displayName(), err))
+			return
+		}
+		d.addEvent(fmt.Sprintf("✅ %s %s wykonany", action, m.displayName()))
+	}()
+}
+
+// Zbiera próbki aktywności logów oraz CPU/RSS dla wszystkich monitorów
+func (d *Dashboard) sample() {
+	now := time.Now()
+	tree := readProcTree()
+
+	// Lista mogła się zmienić po scale - próbki usuniętych kopii nie są potrzebne
+	d.monitors = d.source()
+	if d.selected >= len(d.monitors) {
+		d.selected = len(d.monitors) - 1
+	}
+	if d.selected < 0 {
+		d.selected = 0
+	}
+	samples := make(map[*Monitor]*tuiSamples, len(d.monitors))
+	for _, m := range d.monitors {
+		s := d.samples[m]
+		if s == nil {
+			s = &tuiSamples{}
+		}
+		samples[m] = s
+	}
+	d.samples = samples
+
+	// Status pobierany tutaj, a nie przy rysowaniu - render nie czeka na zajęte monitory
+	statuses := collectStatuses(d.monitors)
+	d.statuses = make(map[*Monitor]ProcessStatus, len(d.monitors))
+	for i, m := range d.monitors {
+		d.statuses[m] = statuses[i]
+	}
+
+	for _, m := range d.monitors {
+		s := samples[m]
+
+		if info, err := os.Stat(m.logFile); err == nil {
+			delta := info.Size() - s.logSize
+			if s.logSize == 0 || delta < 0 {
+				delta = 0 // Pierwsza próbka lub rotacja logu
+			}
+			s.logSize = info.Size()
+			s.activity = append(s.activity, delta)
+			if len(s.activity) > tuiSparkWidth {
+				s.activity = s.activity[len(s.activity)-tuiSparkWidth:]
+			}
+		}
+
+		pid := m.pid()
+		if pid == 0 {
+			s.cpuTicks, s.cpuPct, s.rssKB = 0, 0, 0
+			continue
+		}
+		ticks, rss := tree.usage(pid)
+		if !s.cpuAt.IsZero() && ticks >= s.cpuTicks {
+			// USER_HZ = 100 taktów na sekundę
+			elapsed := now.Sub(s.cpuAt).Seconds()
+			s.cpuPct = float64(ticks-s.cpuTicks) / (elapsed * 100) * 100
+		}
+		s.cpuTicks, s.cpuAt, s.rssKB = ticks, now, rss
+	}
+}
+
+// Rysuje cały ekran
+func (d *Dashboard) render() {
+	rows, cols := terminalSize(d.term)
+	var lines []string
+
+	lines = append(lines, ansiBold+fmt.Sprintf("Monitor procesów - %d procesów   %s", len(d.monitors), time.Now().Format("15:04:05"))+ansiReset)
+	lines = append(lines, ansiDim+"↑/↓ wybór   r restart   s stop   u start   q wyjście"+ansiReset)
+	lines = append(lines, "")
+	lines = append(lines, ansiBold+fmt.Sprintf("  %-20s %-8s %7s %8s %6s %9s %7s  %s",
+		"NAZWA", "STAN", "PID", "UPTIME", "CPU%", "RSS", "RESTART", "AKTYWNOŚĆ LOGÓW")+ansiReset)
+
+	for i, m := range d.monitors {
+		st := d.statuses[m]
+		s := d.samples[m]
+
+		pid, uptime, cpu, rss := "-", "-", "-", "-"
+		if st.PID > 0 {
+			pid = strconv.Itoa(st.PID)
+			uptime = formatDuration(st.UptimeSeconds)
+			cpu = fmt.Sprintf("%.1f", s.cpuPct)
+			rss = formatKB(s.rssKB)
+		}
+
+		marker := "  "
+		if i == d.selected {
+			marker = "> "
+		}
+		row := fmt.Sprintf("%s%-20s %s%-8s%s %7s %8s %6s %9s %7s  %s",
+			marker, truncate(st.Name, 20), stateColor(st.State), st.State, ansiReset,
+			pid, uptime, cpu, rss, fmt.Sprintf("%d/%d", st.Restarts1h, st.Restarts24h), sparkline(s.activity))
+		if i == d.selected {
+			row = ansiReverse + row + ansiReset
+		}
+		lines = append(lines, row)
+	}
+
+	// Pozostałe miejsce: podgląd logu wybranego procesu i komunikaty monitora
+	free := rows - len(lines) - 4
+	if free < 2 {
+		free = 2
+	}
+	tailRows := free * 2 / 3
+	eventRows := free - tailRows
+
+	if len(d.monitors) > 0 {
+		m := d.monitors[d.selected]
+		lines = append(lines, "")
+		var tail []string
+		if m.logFile == "" {
+			// Monitor bez log_file sprawdza tylko watchdog
+			lines = append(lines, ansiBold+fmt.Sprintf("── Log: %s", m.displayName())+ansiReset)
+			tail = []string{ansiDim + "brak log_file - życie procesu sprawdza watchdog" + ansiReset}
+		} else {
+			lines = append(lines, ansiBold+fmt.Sprintf("── Log: %s (%s)", m.displayName(), m.logFile)+ansiReset)
+			tail = tailFile(m.logFile, tailRows)
+		}
+		for len(tail) < tailRows {
+			tail = append(tail, "")
+		}
+		lines = append(lines, tail...)
+	}
+
+	lines = append(lines, ansiBold+"── Zdarzenia monitora"+ansiReset)
+	lines = append(lines, d.lastEvents(eventRows)...)
+
+	if d.message != "" {
+		lines = append(lines, ansiCyan+d.message+ansiReset)
+	}
+
+	var b strings.Builder
+	b.WriteString(ansiHome)
+	for i, line := range lines {
+		if i >= rows {
+			break
+		}
+		b.WriteString(clipANSI(line, cols))
+		b.WriteString(ansiClearLine)
+		if i < rows-1 && i < len(lines)-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	b.WriteString(ansiClearDown)
+	fmt.Fprint(d.term, b.String())
+}
+
+// Kolor stanu procesu
+func stateColor(state string) string {
+	switch state {
+	case stateRunning:
+		return ansiGreen
+	case stateFailed:
+		return ansiRed
+	case stateHeld, stateBusy:
+		return ansiCyan
+	default:
+		return ansiYellow
+	}
+}
+
+// Rysuje wykres aktywności (skala względem maksimum w oknie)
+func sparkline(values []int64) string {
+	var max int64
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		switch {
+		case v == 0:
+			b.WriteRune(' ')
+		case max == 0:
+			b.WriteRune(sparkChars[0])
+		default:
+			b.WriteRune(sparkChars[int(v*int64(len(sparkChars)-1)/max)])
+		}
+	}
+	return b.String()
+}
+
+// Formatuje rozmiar w kilobajtach
+func formatKB(kb int64) string {
+	switch {
+	case kb >= 1024*1024:
+		return fmt.Sprintf("%.1fG", float64(kb)/(1024*1024))
+	case kb >= 1024:
+		return fmt.Sprintf("%.1fM", float64(kb)/1024)
+	default:
+		return fmt.Sprintf("%dK", kb)
+	}
+}
+
+// Skraca tekst do podanej liczby znaków
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// Przycina linię do szerokości ekranu, nie licząc sekwencji ANSI
+func clipANSI(line string, width int) string {
+	var b strings.Builder
+	visible := 0
+	inEscape := false
+	for _, r := range line {
+		switch {
+		case r == 0x1b:
+			inEscape = true
+		case inEscape:
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+				inEscape = false
+			}
+		default:
+			if visible >= width {
+				continue
+			}
+			visible++
+		}
+		b.WriteRune(r)
+	}
+	return b.String() + ansiReset
+}
+
+// Zwraca ostatnie linie pliku bez znaków sterujących
+func tailFile(path string, n int) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return []string{ansiDim + err.Error() + ansiReset}
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil
+	}
+	offset := info.Size() - tuiTailReadBytes
+	if offset < 0 {
+		offset = 0
+	}
+	buf := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(buf, offset); err != nil && err != io.EOF {
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimRight(string(buf), "\n"), "\n")
+	if offset > 0 && len(lines) > 1 {
+		lines = lines[1:] // Pierwsza linia może być ucięta
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for i, line := range lines {
+		lines[i] = strings.Map(func(r rune) rune {
+			if r < 0x20 && r != '\t' {
+				return -1
+			}
+			return r
+		}, line)
+	}
+	return lines
+}
+
+// Migawka procesów z /proc: rodzic, czas CPU i RSS każdego PID
+type procTree struct {
+	children map[int][]int
+	ticks    map[int]uint64
+	rssKB    map[int]int64
+}
+
+// Czyta /proc/<pid>/stat wszystkich procesów
+func readProcTree() *procTree {
+	t := &procTree{
+		children: make(map[int][]int),
+		ticks:    make(map[int]uint64),
+		rssKB:    make(map[int]int64),
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return t
+	}
+	pageKB := int64(os.Getpagesize() / 1024)
+
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		st, err := procfs.ReadStat(pid)
+		if err != nil {
+			continue
+		}
+		t.children[st.PPID] = append(t.children[st.PPID], pid)
+		t.ticks[pid] = st.UTime + st.STime
+		t.rssKB[pid] = st.RSSPages * pageKB
+	}
+
+	return t
+}
+
+// Sumuje CPU i RSS procesu oraz wszystkich jego potomków
+func (t *procTree) usage(pid int) (uint64, int64) {
+	var ticks uint64
+	var rss int64
+	queue := []int{pid}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		ticks += t.ticks[p]
+		rss += t.rssKB[p]
+		queue = append(queue, t.children[p]...)
+	}
+	return ticks, rss
+}
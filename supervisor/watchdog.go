@@ -0,0 +1,281 @@
+package supervisor
+
+import (
+	"fmt"
+	"log"
+	"monitor_mutex/config"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sygnały gotowości i życia wysyłane przez aplikację
+//
+// Gniazdo datagramowe przyjmuje komunikaty w formacie sd_notify (linie KLUCZ=wartość),
+// więc aplikacje używające sd_notify(3), systemd-notify albo bibliotek jak go-systemd
+// działają pod monitorem bez zmian. Tryb pliku heartbeat jest dla aplikacji,
+// które potrafią tylko dotknąć pliku (touch).
+type watchdog struct {
+	notify        bool
+	heartbeatFile string
+	timeout       time.Duration // Cisza bez sygnału życia do restartu (0 = bez kontroli)
+	readyTimeout  time.Duration // Czas na READY=1 od startu (0 = nie czekaj)
+
+	socketPath string        // Ścieżka gniazda NOTIFY_SOCKET
+	tempDir    string        // Katalog tymczasowy do usunięcia (gdy ścieżka nie była zadana)
+	conn       *net.UnixConn // Gniazdo (nil = nie nasłuchuje)
+
+	mu       sync.Mutex
+	ready    bool      // Aplikacja wysłała READY=1
+	lastPing time.Time // Ostatni WATCHDOG=1 lub dotknięcie pliku
+	status   string    // Ostatni STATUS=
+	trigger  bool      // Aplikacja zażądała restartu (WATCHDOG=trigger)
+}
+
+// Maksymalna długość ścieżki gniazda Unix (sun_path bez kończącego zera)
+const maxSocketPath = 107
+
+// Sprawdza konfigurację watchdoga
+func newWatchdog(cfg config.WatchdogConfig, interval time.Duration) (*watchdog, error) {
+	w := &watchdog{
+		notify:        cfg.Notify,
+		heartbeatFile: cfg.HeartbeatFile,
+		timeout:       time.Duration(cfg.Timeout) * time.Second,
+		readyTimeout:  time.Duration(cfg.ReadyTimeout) * time.Second,
+	}
+
+	switch {
+	case !w.notify && w.heartbeatFile == "":
+		return nil, fmt.Errorf("wymaga notify: true lub heartbeat_file")
+	case cfg.Timeout < 0 || cfg.ReadyTimeout < 0:
+		return nil, fmt.Errorf("timeout i ready_timeout nie mogą być ujemne")
+	case w.readyTimeout > 0 && !w.notify:
+		return nil, fmt.Errorf("ready_timeout wymaga notify: true (gotowość zgłasza READY=1)")
+	case w.heartbeatFile != "" && w.timeout == 0:
+		return nil, fmt.Errorf("heartbeat_file wymaga timeout")
+	case w.timeout > 0 && w.timeout < 2*interval:
+		return nil, fmt.Errorf("timeout (%v) powinien wynosić co najmniej 2 × interval (%v)", w.timeout, interval)
+	}
+	return w, nil
+}
+
+// Zwraca ścieżkę gniazda dla procesu o podanej nazwie w katalogu dir
+func notifySocketPath(dir, name string) string {
+	return filepath.Join(dir, "monitor_mutex."+safeName(name)+".notify")
+}
+
+// Nazwa procesu bezpieczna w nazwie pliku (gniazda, jednostki systemd)
+func safeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// Otwiera gniazdo NOTIFY_SOCKET i zaczyna przyjmować komunikaty
+func (w *watchdog) listen(owner *launchSpec) error {
+	if !w.notify {
+		return nil
+	}
+
+	// Stała ścieżka przetrwa restart monitora - przejęty proces dalej trafi do gniazda
+	if w.socketPath == "" || len(w.socketPath) > maxSocketPath {
+		dir, err := os.MkdirTemp("", "monitor_mutex-")
+		if err != nil {
+			return fmt.Errorf("nie można utworzyć katalogu gniazda notify: %v", err)
+		}
+		if err := os.Chmod(dir, 0711); err != nil {
+			return err
+		}
+		w.tempDir = dir
+		w.socketPath = filepath.Join(dir, "notify")
+	}
+
+	os.Remove(w.socketPath) // Pozostałość po poprzedniej instancji
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: w.socketPath, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("nie można utworzyć gniazda notify %s: %v", w.socketPath, err)
+	}
+	w.conn = conn
+
+	// Proces uruchamiany jako inny użytkownik też musi móc pisać do gniazda
+	if owner != nil && owner.credential != nil {
+		os.Chown(w.socketPath, int(owner.credential.Uid), int(owner.credential.Gid))
+	}
+	if err := os.Chmod(w.socketPath, 0660); err != nil {
+		conn.Close()
+		return err
+	}
+
+	go w.serve()
+	return nil
+}
+
+// Odbiera komunikaty aż do zamknięcia gniazda
+func (w *watchdog) serve() {
+	buf := make([]byte, 4096)
+	for {
+		n, err := w.conn.Read(buf)
+		if err != nil {
+			return
+		}
+		w.handle(string(buf[:n]), time.Now())
+	}
+}
+
+// Przetwarza jeden komunikat sd_notify (linie KLUCZ=wartość; nieznane klucze są pomijane)
+func (w *watchdog) handle(msg string, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, line := range strings.Split(msg, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "READY":
+			if value == "1" && !w.ready {
+				w.ready = true
+				w.lastPing = now // Gotowość jest też sygnałem życia
+			}
+		case "WATCHDOG":
+			switch value {
+			case "1":
+				w.lastPing = now
+			case "trigger":
+				w.trigger = true
+			}
+		case "STATUS":
+			w.status = value
+		}
+	}
+}
+
+// Zamyka gniazdo i sprząta po nim
+func (w *watchdog) close() {
+	if w.conn != nil {
+		w.conn.Close()
+		os.Remove(w.socketPath)
+		w.conn = nil
+	}
+	if w.tempDir != "" {
+		os.RemoveAll(w.tempDir)
+		w.tempDir = ""
+		w.socketPath = ""
+	}
+}
+
+// Nowy proces: zapomnij gotowość i sygnały poprzedniego
+func (w *watchdog) reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ready = false
+	w.lastPing = time.Time{}
+	w.status = ""
+	w.trigger = false
+}
+
+// Zmienne środowiska dla procesu (jak ustawia je systemd dla Type=notify)
+func (w *watchdog) env() []string {
+	var env []string
+	if w.conn != nil {
+		env = append(env, "NOTIFY_SOCKET="+w.socketPath)
+		if w.timeout > 0 {
+			env = append(env, "WATCHDOG_USEC="+strconv.FormatInt(w.timeout.Microseconds(), 10))
+		}
+	}
+	if w.heartbeatFile != "" {
+		env = append(env, "HEARTBEAT_FILE="+w.heartbeatFile)
+	}
+	return env
+}
+
+// Sprawdza gotowość i życie procesu uruchomionego o startedAt; zwraca powód restartu albo ""
+func (w *watchdog) check(startedAt, now time.Time) string {
+	// Plik heartbeat: czas modyfikacji nowszy niż start procesu jest sygnałem życia
+	if w.heartbeatFile != "" {
+		if info, err := os.Stat(w.heartbeatFile); err == nil && info.ModTime().After(startedAt) {
+			w.mu.Lock()
+			if info.ModTime().After(w.lastPing) {
+				w.lastPing = info.ModTime()
+			}
+			w.mu.Unlock()
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.trigger {
+		w.trigger = false
+		return "aplikacja zgłosiła WATCHDOG=trigger"
+	}
+	if w.readyTimeout > 0 && !w.ready && now.Sub(startedAt) > w.readyTimeout {
+		return fmt.Sprintf("brak READY=1 w ciągu %v od startu", w.readyTimeout)
+	}
+	if w.timeout > 0 {
+		// Przed pierwszym sygnałem liczy się od startu procesu
+		last := w.lastPing
+		if last.Before(startedAt) {
+			last = startedAt
+		}
+		if silence := now.Sub(last); silence > w.timeout {
+			return fmt.Sprintf("brak sygnału watchdog przez %v (limit: %v)", silence.Round(time.Second), w.timeout)
+		}
+	}
+	return ""
+}
+
+// Proces przejęty po restarcie monitora: gotowy, sygnały liczone od teraz
+func (w *watchdog) adopt(now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ready = true
+	w.lastPing = now
+}
+
+// Czy proces jeszcze nie zgłosił gotowości, choć powinien
+func (w *watchdog) starting() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.notify && w.readyTimeout > 0 && !w.ready
+}
+
+// Stan watchdoga do statusu
+func (w *watchdog) snapshot(now time.Time) *WatchdogStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	st := &WatchdogStatus{Ready: w.ready, Status: w.status}
+	if !w.lastPing.IsZero() {
+		st.LastPingSeconds = int64(now.Sub(w.lastPing).Seconds())
+	} else {
+		st.LastPingSeconds = -1
+	}
+	return st
+}
+
+// Uruchamia gniazdo notify przed pierwszym startem procesu
+func (m *Monitor) startWatchdog() {
+	if m.watchdog == nil {
+		return
+	}
+	if err := m.watchdog.listen(&m.launch); err != nil {
+		log.Printf("Watchdog %s: %v - sygnały sd_notify nie będą odbierane", m.displayName(), err)
+		return
+	}
+	if m.watchdog.conn != nil {
+		fmt.Printf("Gniazdo NOTIFY_SOCKET: %s\n", m.watchdog.socketPath)
+	}
+	if m.watchdog.heartbeatFile != "" {
+		fmt.Printf("Plik heartbeat: %s\n", m.watchdog.heartbeatFile)
+	}
+}